@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
+
+	"github.com/rushteam/gosql/scanner"
 )
 
 const (
@@ -19,16 +23,42 @@ const (
 var tagKey = "db"
 var identKey = "`"
 
+//SafeMode reject raw sql fragments ("[#]", string keys without values, string sub query)
+//unless they are marked trusted by Raw, and only accept plain identifiers
+var SafeMode = false
+
+//Raw a trusted sql fragment, it can be used as a where key or a sub query
+//eg: gosql.Where(gosql.Raw("age > ?"), 18)
+type Raw string
+
+//joinLogics allowed logic of join on in safe mode
+var joinLogics = map[string]bool{
+	"=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+}
+
+//orderDirections allowed directions of order by
+var orderDirections = map[string]bool{
+	"":                 true,
+	"ASC":              true,
+	"DESC":             true,
+	"NULLS FIRST":      true,
+	"NULLS LAST":       true,
+	"ASC NULLS FIRST":  true,
+	"ASC NULLS LAST":   true,
+	"DESC NULLS FIRST": true,
+	"DESC NULLS LAST":  true,
+}
+
 //SQLSegments ...
 type SQLSegments struct {
 	table   []TbName
-	fields  []string
+	fields  []interface{}
 	flags   []string
 	join    []map[string]string
 	where   Clause
-	groupBy []string
+	groupBy []interface{}
 	having  Clause
-	orderBy []string
+	orderBy []interface{}
 	limit   struct {
 		limit  int
 		offset int
@@ -38,7 +68,17 @@ type SQLSegments struct {
 	returning bool
 	// params    []interface{}
 	params []map[string]interface{}
-	render struct {
+	//columns to write (only) or not (omit) on insert/update
+	only []string
+	omit []string
+	//do not fill AutoFieldUpdatedAt, the timestamps are filled by the model
+	noAutoTime bool
+	//scope of soft deleted records and delete them really
+	trashed     uint8
+	forceDelete bool
+	//the version column of optimistic locking, it is always written
+	version string
+	render  struct {
 		args []interface{}
 	}
 	//sql cmd type: select|insert|repalce|update|delete
@@ -77,8 +117,16 @@ func (s *SQLSegments) Table(name interface{}) *SQLSegments {
 
 //Field SQLSegments
 func (s *SQLSegments) Field(fields ...string) *SQLSegments {
-	if len(fields) > 0 {
-		s.fields = append(s.fields, fields...)
+	for _, v := range fields {
+		s.fields = append(s.fields, v)
+	}
+	return s
+}
+
+//FieldExpr SQLSegments
+func (s *SQLSegments) FieldExpr(exprs ...Expr) *SQLSegments {
+	for _, v := range exprs {
+		s.fields = append(s.fields, v)
 	}
 	return s
 }
@@ -133,18 +181,56 @@ func (s *SQLSegments) addJoin(typ string, table string, conditionA, logic, condi
 	return s
 }
 
-//OrderBy SQLSegments
+//OrderBy SQLSegments, a comma separated list is split into fields, eg: OrderBy("score DESC, id ASC"),
+//it panics on building if a direction is not allowed, check the input of user by ValidOrderBy
 func (s *SQLSegments) OrderBy(fields ...string) *SQLSegments {
-	if len(fields) > 0 {
-		s.orderBy = append(s.orderBy, fields...)
+	for _, v := range fields {
+		for _, f := range strings.Split(v, ",") {
+			s.orderBy = append(s.orderBy, f)
+		}
 	}
 	return s
 }
 
+//ValidOrderBy check an order by (eg: from a request) before building,
+//the fields must be plain identifiers (eg: t.score) and the directions are ASC/DESC and NULLS FIRST/LAST
+//eg: ValidOrderBy("score DESC, id")
+func ValidOrderBy(orderBy string) error {
+	for _, f := range strings.Split(orderBy, ",") {
+		vv := strings.Fields(f)
+		if len(vv) == 0 {
+			return fmt.Errorf("gosql: order by field can not be empty: %q", orderBy)
+		}
+		for _, name := range strings.Split(vv[0], ".") {
+			if !isPlainIdent(name) {
+				return fmt.Errorf("gosql: order by field is not allowed: %q", vv[0])
+			}
+		}
+		if direction := strings.ToUpper(strings.Join(vv[1:], " ")); !orderDirections[direction] {
+			return fmt.Errorf("gosql: order by direction is not allowed: %q", direction)
+		}
+	}
+	return nil
+}
+
+//OrderByExpr SQLSegments
+func (s *SQLSegments) OrderByExpr(expr Expr, direction ...string) *SQLSegments {
+	s.orderBy = append(s.orderBy, orderExpr{expr, strings.Join(direction, " ")})
+	return s
+}
+
 //GroupBy SQLSegments
 func (s *SQLSegments) GroupBy(fields ...string) *SQLSegments {
-	if len(fields) > 0 {
-		s.groupBy = append(s.groupBy, fields...)
+	for _, v := range fields {
+		s.groupBy = append(s.groupBy, v)
+	}
+	return s
+}
+
+//GroupByExpr SQLSegments
+func (s *SQLSegments) GroupByExpr(exprs ...Expr) *SQLSegments {
+	for _, v := range exprs {
+		s.groupBy = append(s.groupBy, v)
 	}
 	return s
 }
@@ -178,17 +264,51 @@ type Clause struct {
 	key    interface{}
 	val    interface{}
 	logic  string
+	not    bool
 	clause []*Clause
+	//mapper resolve the filter structs, scanner.DefaultMapper if nil
+	mapper *scanner.Mapper
 }
 
 func (p *Clause) addClause(logic string, key interface{}, vals ...interface{}) *Clause {
-	var c = &Clause{}
+	var c = &Clause{mapper: p.mapper}
 	c.logic = logic
 	switch k := key.(type) {
 	case func(*Clause):
 		k(c)
 		// p.clause = append(p.clause, c)
+	case *Clause:
+		if k == nil || (k.key == nil && len(k.clause) == 0) {
+			return p
+		}
+		c.key, c.val, c.not, c.clause = k.key, k.val, k.not, k.clause
+		c.inheritMapper()
+	case Expr:
+		c.key = k
+		if len(vals) > 0 {
+			c.val = vals[0]
+		}
+	case map[string]interface{}:
+		c.whereMap(k)
+		if len(c.clause) == 0 {
+			return p
+		}
 	default:
+		if isFilterStruct(key) {
+			m := p.mapper
+			if m == nil {
+				m = scanner.DefaultMapper
+			}
+			filter, err := m.ResolveStructFilter(key)
+			if err != nil {
+				panic(filterError{err})
+			}
+			c.whereMap(filter)
+			if len(c.clause) == 0 {
+				return p
+			}
+			break
+		}
 		c.key = key
 		if len(vals) > 0 {
 			c.val = vals[0]
@@ -199,7 +319,66 @@ func (p *Clause) addClause(logic string, key interface{}, vals ...interface{}) *
 	return p
 }
 
-//Where ..
+//inheritMapper set the mapper to the nested conditions built out of a session, eg: gosql.Or(...)
+func (p *Clause) inheritMapper() {
+	for _, c := range p.clause {
+		if c.mapper == nil {
+			c.mapper = p.mapper
+			c.inheritMapper()
+		}
+	}
+}
+
+//filterError the filter struct of condition can not be resolved
+type filterError struct {
+	err error
+}
+
+func (e filterError) Error() string {
+	return e.err.Error()
+}
+
+//whereMap add conditions from a map, nil value means IS NULL and slice value means IN
+func (p *Clause) whereMap(m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	//keep conditions order in golang-map
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := m[k]
+		if strings.HasPrefix(k, "[") {
+			p.Where(k, v)
+			continue
+		}
+		if v == nil {
+			p.Where("[is]"+k, nil)
+			continue
+		}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			//an empty slice is always false (1 = 0)
+			p.Where(Col(k).In(v))
+			continue
+		}
+		p.Where(k, v)
+	}
+}
+
+//isFilterStruct check the key is a struct or a pointer to struct
+func isFilterStruct(key interface{}) bool {
+	rt := reflect.TypeOf(key)
+	if rt == nil {
+		return false
+	}
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.Struct
+}
+
+//Where add a condition, the filter struct is resolved by the naming of session (scanner.DefaultMapper out of a session),
+//it panics if the filter struct can not be resolved (eg: two fields of the same column), the methods of Session return the error
 func (p *Clause) Where(key interface{}, vals ...interface{}) *Clause {
 	p.addClause("AND", key, vals...)
 	return p
@@ -219,44 +398,37 @@ func (p *Clause) Build(i int) (string, []interface{}) {
 		sql += " " + p.logic
 	}
 	switch k := p.key.(type) {
+	case Expr:
+		context, exprArgs := k.Build()
+		sql += " " + context
+		args = append(args, exprArgs...)
+		if p.val != nil {
+			sql += " = ?"
+			args = append(args, p.val)
+		}
+	case Raw:
+		context, rawArgs := buildRaw(p.mapper, string(k), p.val)
+		sql += " " + context
+		args = append(args, rawArgs...)
 	case string:
-		r, _ := regexp.Compile(`\[(\>\=|\<\=|\>|\<|\<\>|\!\=|\=|\~|\!\~|like|!like|in|!in|is|!is|exists|!exists|#)\]?([a-zA-Z0-9_.\-\=\s\?\(\)]*)`)
+		r, _ := regexp.Compile(`\[(\>\=|\<\=|\>|\<|\<\>|\!\=|\=|\~|\!\~|like|!like|in|!in|is|!is|exists|!exists|between|!between|#)\]?([a-zA-Z0-9_.\-\=\s\?\(\)]*)`)
 		match := r.FindStringSubmatch(k)
 		var context string
 		if len(match) > 0 {
 			// fmt.Println(len(match), match[1])
 			switch match[1] {
-			case "~", "like":
-				context = buildIdent(match[2]) + " LIKE ?"
-				args = append(args, p.val)
-			case "!~", "!like":
-				context = buildIdent(match[2]) + " NOT LIKE ?"
-				args = append(args, p.val)
-			case ">", ">=", "<", "<=", "<>", "!=", "=":
-				context = buildIdent(match[2]) + " " + match[1] + " ?"
-				args = append(args, p.val)
-			case "in", "!in":
-				var holder string
-				if reflect.TypeOf(p.val).Kind() == reflect.Slice {
-					v := reflect.ValueOf(p.val)
-					holder = buildPlaceholder(v.Len(), "?", " ,")
-					for n := 0; n < v.Len(); n++ {
-						args = append(args, v.Index(n).Interface())
-					}
-				} else {
-					holder = "?"
-					args = append(args, p.val)
-				}
-				context += buildIdent(match[2])
-				if match[1] == "!in" {
-					context += " NOT"
-				}
-				context += " IN (" + holder + ")"
+			case "~", "like", "!~", "!like", ">", ">=", "<", "<=", "<>", "!=", "=", "in", "!in", "is", "!is", "between", "!between":
+				var condArgs []interface{}
+				context, condArgs = buildCondition(match[1], match[2], p.val)
+				args = append(args, condArgs...)
 			case "exists", "!exists":
 				var sub string
 				switch p.val.(type) {
+				case Raw:
+					sub = string(p.val.(Raw))
 				case string:
 					sub = p.val.(string)
+					checkRaw(sub)
 				case func(s *SQLSegments):
 					s := NewSQLSegment()
 					p.val.(func(s *SQLSegments))(s)
@@ -267,30 +439,16 @@ func (p *Clause) Build(i int) (string, []interface{}) {
 					context += "NOT "
 				}
 				context += "EXISTS (" + sub + ")"
-			case "is":
-				if p.val == nil {
-					context = buildIdent(match[2]) + " IS NULL"
-				} else {
-					context = buildIdent(match[2]) + " IS ?"
-					args = append(args, p.val)
-				}
-			case "!is":
-				if p.val == nil {
-					context = buildIdent(match[2]) + " IS NOT NULL"
-				} else {
-					context = buildIdent(match[2]) + " IS NOT ?"
-					args = append(args, p.val)
-				}
 			case "#":
-				context = match[2]
-				if reflect.TypeOf(p.val).Kind() == reflect.Slice {
-					v := reflect.ValueOf(p.val)
-					for n := 0; n < v.Len(); n++ {
-						args = append(args, v.Index(n).Interface())
-					}
+				checkRaw(k)
+				var rawArgs []interface{}
+				if isNamedArg(p.val) {
+					//named parameters, eg: "[#]age > :age"
+					context, rawArgs = buildRaw(p.mapper, k[strings.Index(k, "]")+1:], p.val)
 				} else {
-					args = append(args, p.val)
+					context, rawArgs = buildRaw(p.mapper, match[2], p.val)
 				}
+				args = append(args, rawArgs...)
 			}
 			sql += " " + context
 		} else {
@@ -298,10 +456,14 @@ func (p *Clause) Build(i int) (string, []interface{}) {
 				sql += " " + buildIdent(k) + " = ?"
 				args = append(args, p.val)
 			} else {
+				checkRaw(k)
 				sql += " " + k
 			}
 		}
 	case nil:
+		if p.not {
+			sql += " NOT"
+		}
 		sql += " ("
 		for j, c := range p.clause {
 			part, arg := c.Build(j)
@@ -313,7 +475,67 @@ func (p *Clause) Build(i int) (string, []interface{}) {
 	return sql, args
 }
 
-//Where ..
+//buildCondition build a condition of column by the symbol, eg: ">=", "in", "between"
+func buildCondition(op, column string, val interface{}) (string, []interface{}) {
+	var args []interface{}
+	var context string
+	switch op {
+	case "~", "like":
+		context = buildIdent(column) + " LIKE ?"
+		args = append(args, val)
+	case "!~", "!like":
+		context = buildIdent(column) + " NOT LIKE ?"
+		args = append(args, val)
+	case ">", ">=", "<", "<=", "<>", "!=", "=":
+		context = buildIdent(column) + " " + op + " ?"
+		args = append(args, val)
+	case "in", "!in":
+		var holder string
+		if reflect.TypeOf(val).Kind() == reflect.Slice {
+			v := reflect.ValueOf(val)
+			holder = buildPlaceholder(v.Len(), "?", " ,")
+			for n := 0; n < v.Len(); n++ {
+				args = append(args, v.Index(n).Interface())
+			}
+		} else {
+			holder = "?"
+			args = append(args, val)
+		}
+		context += buildIdent(column)
+		if op == "!in" {
+			context += " NOT"
+		}
+		context += " IN (" + holder + ")"
+	case "is":
+		if val == nil {
+			context = buildIdent(column) + " IS NULL"
+		} else {
+			context = buildIdent(column) + " IS ?"
+			args = append(args, val)
+		}
+	case "!is":
+		if val == nil {
+			context = buildIdent(column) + " IS NOT NULL"
+		} else {
+			context = buildIdent(column) + " IS NOT ?"
+			args = append(args, val)
+		}
+	case "between", "!between":
+		v := reflect.ValueOf(val)
+		if v.Kind() != reflect.Slice || v.Len() != 2 {
+			panic(fmt.Sprintf("[%s] must be have two values: %v", op, val))
+		}
+		context = buildIdent(column)
+		if op == "!between" {
+			context += " NOT"
+		}
+		context += " BETWEEN ? AND ?"
+		args = append(args, v.Index(0).Interface(), v.Index(1).Interface())
+	}
+	return context, args
+}
+
+//Where add a condition, see Clause.Where
 func (s *SQLSegments) Where(key interface{}, vals ...interface{}) *SQLSegments {
 	s.where.Where(key, vals...)
 	return s
@@ -339,6 +561,17 @@ func (s *SQLSegments) buildWhereClause() string {
 	return sql
 }
 
+//scope add a condition to all conditions, the conditions are grouped when they have OR
+func (s *SQLSegments) scope(key interface{}, vals ...interface{}) {
+	for _, c := range s.where.clause {
+		if c.logic == "OR" {
+			s.where.clause = []*Clause{{logic: "AND", clause: s.where.clause}}
+			break
+		}
+	}
+	s.where.Where(key, vals...)
+}
+
 //IsEmptyWhereClause ...
 func (s *SQLSegments) IsEmptyWhereClause() bool {
 	return len(s.where.clause) < 1
@@ -380,10 +613,21 @@ func (s *SQLSegments) buildField() string {
 			if i > 0 {
 				sql += ","
 			}
-			if v == "*" {
-				sql += " " + v
-			} else {
-				sql += " " + buildIdent(v)
+			switch v := v.(type) {
+			case string:
+				if v == "*" {
+					sql += " " + v
+				} else if field, alias := splitAlias(v); alias != "" {
+					//eg: "u.id AS user.id"
+					sql += " " + buildIdent(field) + " AS " + buildAlias(alias)
+				} else {
+					sql += " " + buildIdent(v)
+				}
+			case Expr:
+				sql += " " + s.buildExpr(v)
+				if a, ok := v.(aliasExpr); ok && a.alias() != "" {
+					sql += " AS " + buildAlias(a.alias())
+				}
 			}
 		}
 	}
@@ -405,6 +649,9 @@ func (s *SQLSegments) buildTable() string {
 func (s *SQLSegments) buildJoin() string {
 	var sql string
 	for _, t := range s.join {
+		if SafeMode && !joinLogics[t["logic"]] {
+			panic(fmt.Sprintf("join logic is not allowed in safe mode: %q", t["logic"]))
+		}
 		sql += " " + t["type"] + " " + buildIdent(t["table"]) + " ON " + buildIdent(t["conditionA"]) + " " + t["logic"] + " " + buildIdent(t["conditionB"])
 	}
 	return sql
@@ -418,7 +665,12 @@ func (s *SQLSegments) buildGroupBy() string {
 		if i > 0 {
 			sql += ","
 		}
-		sql += " " + buildIdent(v)
+		switch v := v.(type) {
+		case string:
+			sql += " " + buildIdent(v)
+		case Expr:
+			sql += " " + s.buildExpr(v)
+		}
 	}
 	return sql
 }
@@ -431,10 +683,24 @@ func (s *SQLSegments) buildOrderBy() string {
 		if i > 0 {
 			sql += ","
 		}
-		vv := strings.SplitN(v, " ", 2)
-		sql += " " + buildIdent(vv[0])
-		if len(vv) > 1 {
-			sql += " " + strings.ToUpper(vv[1])
+		var field, direction string
+		switch v := v.(type) {
+		case string:
+			vv := strings.Fields(v)
+			if len(vv) == 0 {
+				panic(fmt.Sprintf("order by field can not be empty: %q", v))
+			}
+			field, direction = buildIdent(vv[0]), strings.Join(vv[1:], " ")
+		case orderExpr:
+			field, direction = s.buildExpr(v.expr), v.direction
+		}
+		direction = strings.ToUpper(direction)
+		if !orderDirections[direction] {
+			panic(fmt.Sprintf("order by direction is not allowed: %q", direction))
+		}
+		sql += " " + field
+		if direction != "" {
+			sql += " " + direction
 		}
 	}
 	return sql
@@ -537,12 +803,8 @@ func (s *SQLSegments) BuildReplace() string {
 func (s *SQLSegments) buildValuesForInsert() string {
 	var sql string
 	var fields []string
-	for i, vals := range s.params {
-		if i == 0 {
-			for arg := range vals {
-				fields = append(fields, arg)
-			}
-		}
+	if len(s.params) > 0 {
+		fields = s.writableKeys(s.params[0])
 	}
 	sql = " ("
 	for i, s := range fields {
@@ -567,6 +829,78 @@ func (s *SQLSegments) buildValuesForInsert() string {
 	return sql
 }
 
+//setParams set values of update which are not set by Set or Params
+func (s *SQLSegments) setParams(vals map[string]interface{}) {
+	params := make(map[string]interface{}, len(vals))
+	for k, v := range vals {
+		params[k] = v
+	}
+	if len(s.params) > 0 {
+		for k, v := range s.params[0] {
+			params[k] = v
+		}
+		s.params[0] = params
+		return
+	}
+	s.params = append(s.params, params)
+}
+
+//hasParam the column is set by Set or Params
+func (s *SQLSegments) hasParam(column string) bool {
+	if len(s.params) == 0 {
+		return false
+	}
+	_, ok := s.params[0][column]
+	return ok
+}
+
+//Only write only these columns on insert/update, other values are ignored
+func (s *SQLSegments) Only(columns ...string) *SQLSegments {
+	s.only = append(s.only, columns...)
+	return s
+}
+
+//Omit do not write these columns on insert/update
+func (s *SQLSegments) Omit(columns ...string) *SQLSegments {
+	s.omit = append(s.omit, columns...)
+	return s
+}
+
+//writable the column is not excluded by Only and Omit
+func (s *SQLSegments) writable(column string) bool {
+	for _, c := range s.omit {
+		if c == column {
+			return false
+		}
+	}
+	if len(s.only) == 0 {
+		return true
+	}
+	for _, c := range s.only {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+//writableKeys sorted keys of values which are writable, eg: "[+]score" is the column score,
+//the version column is not excluded by Only and Omit
+func (s *SQLSegments) writableKeys(vals map[string]interface{}) []string {
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		column := key
+		if strings.HasPrefix(key, "[+]") || strings.HasPrefix(key, "[-]") {
+			column = key[3:]
+		}
+		if s.writable(column) || (column == s.version && column != "") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 //UpdateField for set a field when update sql
 func (s *SQLSegments) UpdateField(key string, val interface{}) *SQLSegments {
 	if len(s.params) == 0 {
@@ -604,6 +938,11 @@ func (s *SQLSegments) buildReturning() string {
 
 //BuildUpdate build a update sql
 func (s *SQLSegments) BuildUpdate() string {
+	//若开启自动填充时间，则填充更新时间
+	if AutoFillCreatedAtAndUpdatedAtField && !s.noAutoTime && len(s.params) > 0 {
+		s.setParams(map[string]interface{}{AutoFieldUpdatedAt: NowFunc()})
+		s.noAutoTime = true
+	}
 	var sql = fmt.Sprintf("UPDATE%s%s%s%s%s%s%s",
 		s.buildFlags(),
 		s.buildTable(),
@@ -632,8 +971,13 @@ func (s *SQLSegments) buildValuesForUpdate() string {
 			panic(fmt.Sprintf("Must be have values after 'UPDATE %s SET'", s.buildTable()))
 		}
 		if i == 0 {
+			keys := s.writableKeys(vals)
+			if len(keys) == 0 {
+				panic(fmt.Sprintf("Must be have values after 'UPDATE %s SET'", s.buildTable()))
+			}
 			j := 0
-			for arg, val := range vals {
+			for _, arg := range keys {
+				val := vals[arg]
 				// fieldSlice = append(fieldSlice, arg)
 				// s.render.args = append(s.render.args, val)
 				if j > 0 {
@@ -641,7 +985,11 @@ func (s *SQLSegments) buildValuesForUpdate() string {
 				}
 
 				match := r.FindStringSubmatch(arg)
-				if len(match) > 1 {
+				if expr, ok := val.(Expr); ok {
+					buffer.WriteString(buildIdent(arg))
+					buffer.WriteString(" = ")
+					buffer.WriteString(s.buildExpr(expr))
+				} else if len(match) > 1 {
 					buffer.WriteString(buildIdent(match[2]))
 					buffer.WriteString(" = ")
 					buffer.WriteString(buildIdent(match[2]))
@@ -664,6 +1012,24 @@ func (s *SQLSegments) buildValuesForUpdate() string {
 	return buffer.String()
 }
 
+//WithTrashed include the soft deleted records
+func (s *SQLSegments) WithTrashed() *SQLSegments {
+	s.trashed = scopeWithTrashed
+	return s
+}
+
+//OnlyTrashed only the soft deleted records
+func (s *SQLSegments) OnlyTrashed() *SQLSegments {
+	s.trashed = scopeOnlyTrashed
+	return s
+}
+
+//ForceDelete delete the records of a soft deleted model really
+func (s *SQLSegments) ForceDelete() *SQLSegments {
+	s.forceDelete = true
+	return s
+}
+
 //Delete for a part of delete sql
 func (s *SQLSegments) Delete() *SQLSegments {
 	return s
@@ -684,9 +1050,93 @@ func (s *SQLSegments) BuildDelete() string {
 	return sql
 }
 
-//buildIdent
+//buildIdent quote a (dotted) identifier and escape the embedded quotes
 func buildIdent(name string) string {
-	return identKey + strings.Replace(name, ".", identKey+"."+identKey, -1) + identKey
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part == "*" && i == len(parts)-1 {
+			continue
+		}
+		if SafeMode && !isPlainIdent(part) {
+			panic(fmt.Sprintf("identifier is not allowed in safe mode: %q", name))
+		}
+		parts[i] = identKey + strings.Replace(part, identKey, identKey+identKey, -1) + identKey
+	}
+	return strings.Join(parts, ".")
+}
+
+//splitAlias split "field AS alias"
+func splitAlias(name string) (string, string) {
+	vv := strings.Fields(name)
+	if len(vv) == 3 && strings.ToUpper(vv[1]) == "AS" {
+		return vv[0], vv[2]
+	}
+	return name, ""
+}
+
+//buildAlias quote an alias, dots are kept in it
+func buildAlias(alias string) string {
+	if SafeMode {
+		for _, part := range strings.Split(alias, ".") {
+			if !isPlainIdent(part) {
+				panic(fmt.Sprintf("alias is not allowed in safe mode: %q", alias))
+			}
+		}
+	}
+	return identKey + strings.Replace(alias, identKey, identKey+identKey, -1) + identKey
+}
+
+//isPlainIdent letters, digits, '_' and '$' only
+func isPlainIdent(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+//checkRaw a raw fragment must be marked by Raw in safe mode
+func checkRaw(fragment string) {
+	if SafeMode {
+		panic(fmt.Sprintf("raw sql is not allowed in safe mode, mark it trusted by gosql.Raw: %q", fragment))
+	}
+}
+
+//buildRaw build a raw fragment with its args, the named parameters of struct are bound by the columns of m
+func buildRaw(m *scanner.Mapper, fragment string, val interface{}) (string, []interface{}) {
+	var args []interface{}
+	switch {
+	case val == nil:
+	case isNamedArg(val):
+		if m == nil {
+			m = scanner.DefaultMapper
+		}
+		query, namedArgs, err := named(m, nil, fragment, val)
+		if err != nil {
+			panic(err)
+		}
+		return query, namedArgs
+	case reflect.TypeOf(val).Kind() == reflect.Slice:
+		v := reflect.ValueOf(val)
+		for n := 0; n < v.Len(); n++ {
+			args = append(args, v.Index(n).Interface())
+		}
+	default:
+		args = append(args, val)
+	}
+	return fragment, args
+}
+
+//buildExpr build an expression and collect its args
+func (s *SQLSegments) buildExpr(expr Expr) string {
+	sql, args := expr.Build()
+	s.render.args = append(s.render.args, args...)
+	return sql
 }
 
 //buildPlaceholder
@@ -747,6 +1197,14 @@ func Columns(fields ...string) Option {
 	}
 }
 
+//ColumnsExpr for set expression columns, eg: gosql.ColumnsExpr(gosql.Case()...As("grade"))
+func ColumnsExpr(exprs ...Expr) Option {
+	return func(s SQLSegments) SQLSegments {
+		s.FieldExpr(exprs...)
+		return s
+	}
+}
+
 //Flag for set flag
 func Flag(flags ...string) Option {
 	return func(s SQLSegments) SQLSegments {
@@ -804,7 +1262,7 @@ func Union(f func(*SQLSegments)) Option {
 	}
 }
 
-//OrderBy ..
+//OrderBy eg: gosql.OrderBy("score DESC, id"), see SQLSegments.OrderBy
 func OrderBy(fields ...string) Option {
 	return func(s SQLSegments) SQLSegments {
 		s.OrderBy(fields...)
@@ -812,6 +1270,14 @@ func OrderBy(fields ...string) Option {
 	}
 }
 
+//OrderByExpr for order by an expression, eg: gosql.OrderByExpr(gosql.Case()..., "DESC")
+func OrderByExpr(expr Expr, direction ...string) Option {
+	return func(s SQLSegments) SQLSegments {
+		s.OrderByExpr(expr, direction...)
+		return s
+	}
+}
+
 //GroupBy ..
 func GroupBy(fields ...string) Option {
 	return func(s SQLSegments) SQLSegments {
@@ -820,6 +1286,14 @@ func GroupBy(fields ...string) Option {
 	}
 }
 
+//GroupByExpr for group by expressions
+func GroupByExpr(exprs ...Expr) Option {
+	return func(s SQLSegments) SQLSegments {
+		s.GroupByExpr(exprs...)
+		return s
+	}
+}
+
 //Offset ..
 func Offset(n int) Option {
 	return func(s SQLSegments) SQLSegments {
@@ -852,7 +1326,7 @@ func Returning() Option {
 	}
 }
 
-//Where ..
+//Where add a condition, see Clause.Where
 func Where(key interface{}, vals ...interface{}) Option {
 	return func(s SQLSegments) SQLSegments {
 		s.Where(key, vals...)
@@ -877,6 +1351,46 @@ func Set(key string, val interface{}) Option {
 	}
 }
 
+//Only write only these columns on insert/update
+func Only(columns ...string) Option {
+	return func(s SQLSegments) SQLSegments {
+		s.Only(columns...)
+		return s
+	}
+}
+
+//Omit do not write these columns on insert/update
+func Omit(columns ...string) Option {
+	return func(s SQLSegments) SQLSegments {
+		s.Omit(columns...)
+		return s
+	}
+}
+
+//WithTrashed include the soft deleted records of model
+func WithTrashed() Option {
+	return func(s SQLSegments) SQLSegments {
+		s.WithTrashed()
+		return s
+	}
+}
+
+//OnlyTrashed only the soft deleted records of model
+func OnlyTrashed() Option {
+	return func(s SQLSegments) SQLSegments {
+		s.OnlyTrashed()
+		return s
+	}
+}
+
+//ForceDelete delete a soft deleted model really
+func ForceDelete() Option {
+	return func(s SQLSegments) SQLSegments {
+		s.ForceDelete()
+		return s
+	}
+}
+
 //Params ...
 func Params(vals ...map[string]interface{}) Option {
 	return func(s SQLSegments) SQLSegments {
@@ -887,13 +1401,21 @@ func Params(vals ...map[string]interface{}) Option {
 
 //BuildSQL ..
 func buildSQL(cmd uint8, opts ...Option) (string, []interface{}) {
+	s := newSegments(nil, cmd, opts...)
+	return s.Build()
+}
+
+//newSegments apply options to a new SQLSegments, the filter structs of conditions are resolved by m
+func newSegments(m *scanner.Mapper, cmd uint8, opts ...Option) *SQLSegments {
 	s := SQLSegments{
 		cmd: cmd,
 	}
+	s.where.mapper = m
+	s.having.mapper = m
 	for _, opt := range opts {
 		s = opt(s)
 	}
-	return s.Build()
+	return &s
 }
 
 //SelectSQL ..
@@ -1,6 +1,7 @@
 package gosql
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -309,3 +310,184 @@ func TestDeleteSQL(t *testing.T) {
 		t.Errorf("result: %v, want: %v", result, want)
 	}
 }
+
+func TestWhereMap(t *testing.T) {
+	result, args := SelectSQL(
+		Table("table_1"),
+		Where(map[string]interface{}{
+			"name":       "jack",
+			"role":       []int{1, 2},
+			"deleted_at": nil,
+			"[>]age":     18,
+		}),
+		OrWhere(map[string]interface{}{
+			"status": 1,
+		}),
+		Where(map[string]interface{}{}),
+	)
+	want := "SELECT * FROM `table_1` WHERE ( `age` > ? AND `deleted_at` IS NULL AND `name` = ? AND `role` IN (? ,?)) OR ( `status` = ?)"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	if len(args) != 5 {
+		t.Errorf("result: %v, want: %v", len(args), 5)
+	}
+}
+
+func TestWhereStruct(t *testing.T) {
+	type UserFilter struct {
+		Name   string `db:"name"`
+		MinAge int    `db:"age,op:>="`
+		Status *int   `db:"status"`
+		Nick   string
+	}
+	status := 0
+	result, args := SelectSQL(
+		Table("user"),
+		Where(&UserFilter{Name: "jack", MinAge: 18, Status: &status}),
+		Where(UserFilter{}),
+	)
+	want := "SELECT * FROM `user` WHERE ( `age` >= ? AND `name` = ? AND `status` = ?)"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("result: %v, want: %v", len(args), 3)
+	}
+}
+
+func TestWhereEmptySlice(t *testing.T) {
+	type RoleFilter struct {
+		Role []int `db:"role"`
+	}
+	result, args := SelectSQL(
+		Table("t"),
+		Where(map[string]interface{}{"id": []int{}}),
+		Where(&RoleFilter{Role: []int{}}),
+	)
+	want := "SELECT * FROM `t` WHERE ( 1 = 0) AND ( 1 = 0)"
+	if result != want || len(args) != 0 {
+		t.Errorf("result: %v %v, want: %v", result, args, want)
+	}
+}
+
+type badFilter struct {
+	Name string `db:"name"`
+	Nick string `db:"name"`
+}
+
+func TestWhereBadStruct(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("want panic for a bad filter struct")
+		}
+	}()
+	SelectSQL(Table("user"), Where(&badFilter{Name: "jack"}))
+}
+
+func TestBuildIdentEscape(t *testing.T) {
+	result, _ := SelectSQL(
+		Table("tbl"),
+		Columns("t1.*", "na`me"),
+		Where("a` = 1 OR `b", 1),
+		OrderBy("i`d DESC"),
+	)
+	want := "SELECT `t1`.*, `na``me` FROM `tbl` WHERE `a`` = 1 OR ``b` = ? ORDER BY `i``d` DESC"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+}
+
+func TestOrderByDirection(t *testing.T) {
+	result, _ := SelectSQL(Table("tbl"), OrderBy("a  desc", "b asc nulls last", "c nulls first"))
+	want := "SELECT * FROM `tbl` ORDER BY `a` DESC, `b` ASC NULLS LAST, `c` NULLS FIRST"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("want panic for an injected direction")
+		}
+	}()
+	SelectSQL(Table("tbl"), OrderBy("id desc; DROP TABLE tbl"))
+}
+
+func TestOrderByList(t *testing.T) {
+	result, _ := SelectSQL(Table("tbl"), OrderBy("name desc, id asc", "t.score"))
+	want := "SELECT * FROM `tbl` ORDER BY `name` DESC, `id` ASC, `t`.`score`"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	for _, v := range []string{"name desc, id asc", "t.score NULLS LAST", "id"} {
+		if err := ValidOrderBy(v); err != nil {
+			t.Errorf("%q: %v", v, err)
+		}
+	}
+	for _, v := range []string{"", "id,", "id desc; DROP TABLE tbl", "id desc --", "(select 1)", "id desc desc"} {
+		if err := ValidOrderBy(v); err == nil {
+			t.Errorf("%q: want an error", v)
+		}
+	}
+}
+
+func TestSafeMode(t *testing.T) {
+	SafeMode = true
+	defer func() { SafeMode = false }()
+	result, args := SelectSQL(
+		Table("tbl"),
+		Where("id", 1),
+		Where(Raw("v1 = 1")),
+		Where(Raw("v2 = ? OR v3 = ?"), []int{2, 3}),
+		Where("[exists]x", Raw("select 1")),
+	)
+	want := "SELECT * FROM `tbl` WHERE `id` = ? AND v1 = 1 AND v2 = ? OR v3 = ? AND EXISTS (select 1)"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("result: %v, want: %v", len(args), 3)
+	}
+	unsafe := []Option{
+		Where("v1 = 1"),
+		Where("[#]v2 = ?", 2),
+		Where("[exists]x", "select 1"),
+		Where("a`b", 1),
+		Join("t2", "t2.id", "= 1 OR", "t1.id"),
+	}
+	for i, opt := range unsafe {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("case %d: want panic in safe mode", i)
+				}
+			}()
+			SelectSQL(Table("tbl"), opt)
+		}()
+	}
+}
+
+func TestColumnsAlias(t *testing.T) {
+	result, _ := SelectSQL(
+		Columns("u.id AS user.id", "o.id as order__id"),
+		Table(TbName{"users", "u"}),
+		Join("orders", "orders.user_id", "=", "u.id"),
+	)
+	want := "SELECT `u`.`id` AS `user.id`, `o`.`id` AS `order__id` FROM `users` AS `u` JOIN `orders` ON `orders`.`user_id` = `u`.`id`"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+}
+
+func TestOnlyOmit(t *testing.T) {
+	vals := map[string]interface{}{"name": "jack", "age": 10, "[+]score": 1}
+	result, args := UpdateSQL(Table("test"), Params(vals), Omit("age"), Where("id", 1))
+	want := "UPDATE `test` SET `score` = `score` + ?, `name` = ? WHERE `id` = ?"
+	if result != want || !reflect.DeepEqual(args, []interface{}{1, "jack", 1}) {
+		t.Errorf("result: %v %v, want: %v", result, args, want)
+	}
+	result, args = InsertSQL(Table("test"), Params(map[string]interface{}{"name": "jack", "age": 10}), Only("age"))
+	want = "INSERT INTO `test` (`age`) VALUES (?)"
+	if result != want || !reflect.DeepEqual(args, []interface{}{10}) {
+		t.Errorf("result: %v %v, want: %v", result, args, want)
+	}
+}
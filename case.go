@@ -0,0 +1,133 @@
+package gosql
+
+import (
+	"bytes"
+	"strings"
+)
+
+//CaseExpr CASE expression builder
+//searched: gosql.Case().When("[>=]score", 90).Then("A").Else("B")
+//simple: gosql.CaseOf("status").When(1).Then("on").Else("off")
+type CaseExpr struct {
+	value  interface{}
+	whens  []*caseWhen
+	els    interface{}
+	hasEls bool
+	as     string
+}
+
+type caseWhen struct {
+	cond   *Clause
+	val    interface{}
+	result interface{}
+}
+
+//Case start a searched CASE expression
+func Case() *CaseExpr {
+	return &CaseExpr{}
+}
+
+//CaseOf start a simple CASE expression which compares the value of a column or expression
+func CaseOf(value interface{}) *CaseExpr {
+	if v, ok := value.(string); ok {
+		value = Col(v)
+	}
+	return &CaseExpr{value: value}
+}
+
+//When add a WHEN branch, the condition uses the same syntax as Where,
+//for a simple CASE key is the compared value
+func (c *CaseExpr) When(key interface{}, vals ...interface{}) *CaseExpr {
+	w := &caseWhen{}
+	if c.value != nil {
+		w.val = key
+	} else {
+		w.cond = &Clause{}
+		w.cond.Where(key, vals...)
+		if len(w.cond.clause) == 0 {
+			panic("case when must be have a condition")
+		}
+	}
+	c.whens = append(c.whens, w)
+	return c
+}
+
+//Then set the result of the last WHEN branch, Raw and Column are not bind as args
+func (c *CaseExpr) Then(result interface{}) *CaseExpr {
+	if len(c.whens) == 0 {
+		panic("case then must be after when")
+	}
+	c.whens[len(c.whens)-1].result = result
+	return c
+}
+
+//Else set the ELSE result
+func (c *CaseExpr) Else(result interface{}) *CaseExpr {
+	c.els = result
+	c.hasEls = true
+	return c
+}
+
+//As set the alias when it is used in columns
+func (c *CaseExpr) As(alias string) *CaseExpr {
+	c.as = alias
+	return c
+}
+
+func (c *CaseExpr) alias() string {
+	return c.as
+}
+
+//Build impl Expr
+func (c *CaseExpr) Build() (string, []interface{}) {
+	if len(c.whens) == 0 {
+		panic("case must be have a when")
+	}
+	var buffer bytes.Buffer
+	var args []interface{}
+	buffer.WriteString("CASE")
+	if c.value != nil {
+		sql, vArgs := buildValue(c.value)
+		buffer.WriteString(" " + sql)
+		args = append(args, vArgs...)
+	}
+	for _, w := range c.whens {
+		buffer.WriteString(" WHEN ")
+		if w.cond != nil {
+			sql, cArgs := w.cond.clause[0].Build(0)
+			buffer.WriteString(strings.TrimSpace(sql))
+			args = append(args, cArgs...)
+		} else {
+			sql, vArgs := buildValue(w.val)
+			buffer.WriteString(sql)
+			args = append(args, vArgs...)
+		}
+		sql, rArgs := buildValue(w.result)
+		buffer.WriteString(" THEN " + sql)
+		args = append(args, rArgs...)
+	}
+	if c.hasEls {
+		sql, eArgs := buildValue(c.els)
+		buffer.WriteString(" ELSE " + sql)
+		args = append(args, eArgs...)
+	}
+	buffer.WriteString(" END")
+	return buffer.String(), args
+}
+
+//buildValue build a value as a placeholder, Raw and Expr are kept as sql
+func buildValue(val interface{}) (string, []interface{}) {
+	switch v := val.(type) {
+	case Raw:
+		return string(v), nil
+	case Expr:
+		sql, args := v.Build()
+		if _, ok := v.(*CaseExpr); ok {
+			sql = "(" + sql + ")"
+		}
+		return sql, args
+	case nil:
+		return "NULL", nil
+	}
+	return "?", []interface{}{val}
+}
@@ -0,0 +1,61 @@
+package gosql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCaseColumns(t *testing.T) {
+	grade := Case().
+		When("[>=]score", 90).Then("A").
+		When(Col("score").Gte(60)).Then("B").
+		Else("C").As("grade")
+	result, args := SelectSQL(
+		Columns("id"),
+		ColumnsExpr(grade),
+		Table("user"),
+		Where("status", 1),
+		GroupByExpr(CaseOf("status").When(1).Then("on").Else(Raw("'off'"))),
+		OrderByExpr(Case().When("vip", 1).Then(0).Else(1), "desc"),
+	)
+	want := "SELECT `id`, CASE WHEN `score` >= ? THEN ? WHEN `score` >= ? THEN ? ELSE ? END AS `grade` FROM `user` WHERE `status` = ? GROUP BY CASE `status` WHEN ? THEN ? ELSE 'off' END ORDER BY CASE WHEN `vip` = ? THEN ? ELSE ? END DESC"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	wantArgs := []interface{}{90, "A", 60, "B", "C", 1, 1, "on", 1, 0, 1}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+}
+
+func TestCaseWhereAndUpdate(t *testing.T) {
+	result, args := SelectSQL(
+		Table("user"),
+		Where(CaseOf("type").When("a").Then(Col("score")).Else(0), 100),
+	)
+	want := "SELECT * FROM `user` WHERE CASE `type` WHEN ? THEN `score` ELSE ? END = ?"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	wantArgs := []interface{}{"a", 0, 100}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+
+	result, args = UpdateSQL(
+		Table("user"),
+		Set("level", Case().When(func(c *Clause) {
+			c.Where("[>]score", 90)
+			c.OrWhere("vip", 1)
+		}).Then(2).Else(Col("level"))),
+		Where("id", 7),
+	)
+	want = "UPDATE `user` SET `level` = CASE WHEN ( `score` > ? OR `vip` = ?) THEN ? ELSE `level` END WHERE `id` = ?"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	wantArgs = []interface{}{90, 1, 2, 7}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+}
@@ -0,0 +1,84 @@
+//Command gosql-migrate run the migrations of a directory on a db
+//
+//	gosql-migrate -driver mysql -dsn "user:password@tcp(127.0.0.1:3306)/test" -dir migrations up
+//	gosql-migrate ... down
+//	gosql-migrate ... status
+//	gosql-migrate ... goto 20200102030405
+//
+//only the mysql driver is linked, copy this command and import the drivers (and the go migrations) you need
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/rushteam/gosql"
+	"github.com/rushteam/gosql/migrate"
+)
+
+func main() {
+	driver := flag.String("driver", "mysql", "the driver of db")
+	dsn := flag.String("dsn", "", "the dsn of db")
+	dir := flag.String("dir", "migrations", "the directory of SQL migrations")
+	table := flag.String("table", migrate.DefaultTable, "the table of applied versions")
+	timeout := flag.Duration("lock-timeout", 30*time.Second, "how long to wait for the lock of another instance")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] up|down|status|goto VERSION\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *dsn == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*driver, *dsn, *dir, *table, *timeout, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(driver, dsn, dir, table string, timeout time.Duration, args []string) error {
+	db := gosql.NewCluster(gosql.AddDb(driver, dsn))
+	m, err := migrate.New(db, migrate.SetTable(table), migrate.SetLockTimeout(timeout))
+	if err != nil {
+		return err
+	}
+	if err := m.LoadFS(os.DirFS(dir)); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "goto":
+		if len(args) < 2 {
+			return fmt.Errorf("goto: VERSION is required")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("goto: invalid version %s", args[1])
+		}
+		return m.Goto(ctx, version)
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range list {
+			applied := "pending"
+			if st.Applied {
+				applied = st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-20d %-40s %s\n", st.Version, st.Name, applied)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown command %s", args[0])
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -14,6 +15,9 @@ var Debug = false
 //ErrNoRows sql ErrNoRows
 var ErrNoRows = sql.ErrNoRows
 
+//ErrStaleObject the record of a model with version field is changed (or deleted) by others when update
+var ErrStaleObject = errors.New("gosql: stale object, the version of record is changed")
+
 //Result sql Result
 type Result sql.Result
 
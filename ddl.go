@@ -0,0 +1,133 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rushteam/gosql/schema"
+)
+
+//Dialect the dialect of DDL, see SetDialect
+func (c *PoolCluster) Dialect() (schema.Dialect, error) {
+	if c.dialect != nil {
+		return c.dialect, nil
+	}
+	if len(c.pools) == 0 {
+		return nil, errors.New("not found db config")
+	}
+	d, ok := schema.GetDialect(c.pools[0].Driver)
+	if !ok {
+		return nil, fmt.Errorf("gosql: dialect of driver [%s] not found, see SetDialect", c.pools[0].Driver)
+	}
+	return d, nil
+}
+
+//CreateTable create the table of model on primary db
+//eg: db.CreateTable(&User{})
+func (c *PoolCluster) CreateTable(dst interface{}) error {
+	return c.createTable(dst, false)
+}
+
+//CreateTableIfNotExists create the table of model on primary db if it does not exist
+func (c *PoolCluster) CreateTableIfNotExists(dst interface{}) error {
+	return c.createTable(dst, true)
+}
+
+func (c *PoolCluster) createTable(dst interface{}, ifNotExists bool) error {
+	d, err := c.Dialect()
+	if err != nil {
+		return err
+	}
+	s, err := c.Primary()
+	if err != nil {
+		return err
+	}
+	t, err := schema.Parse(d, s.Mapper(), dst)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range schema.CreateTable(d, t, ifNotExists) {
+		if _, err := s.ExecContext(context.Background(), stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//DiffSchema the changes to migrate the tables of models on primary db, see schema.Diff
+func (c *PoolCluster) DiffSchema(models ...interface{}) ([]schema.Change, error) {
+	d, err := c.Dialect()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := d.(schema.Migrator)
+	if !ok {
+		return nil, fmt.Errorf("gosql: dialect %s can not inspect tables", d.Name())
+	}
+	s, err := c.Primary()
+	if err != nil {
+		return nil, err
+	}
+	var changes []schema.Change
+	for _, model := range models {
+		t, err := schema.Parse(m, s.Mapper(), model)
+		if err != nil {
+			return nil, err
+		}
+		current, err := m.Inspect(context.Background(), s, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, schema.Diff(m, t, current)...)
+	}
+	return changes, nil
+}
+
+//AutoMigrate create the tables, add the columns and indexes of models on primary db,
+//the destructive changes (type changes) are not applied, see AutoMigrateDryRun
+//eg: err := db.AutoMigrate(&User{}, &Order{})
+func (c *PoolCluster) AutoMigrate(models ...interface{}) error {
+	changes, err := c.DiffSchema(models...)
+	if err != nil {
+		return err
+	}
+	s, err := c.Primary()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.Destructive || change.SQL == "" {
+			debugPrint("db: AutoMigrate skip: %s", change.Desc)
+			continue
+		}
+		if _, err := s.ExecContext(context.Background(), change.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//AutoMigrateDryRun print the statements of AutoMigrate to w without executing them,
+//the destructive changes are printed as comments
+func (c *PoolCluster) AutoMigrateDryRun(w io.Writer, models ...interface{}) error {
+	changes, err := c.DiffSchema(models...)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		switch {
+		case change.SQL == "":
+			_, err = fmt.Fprintf(w, "-- not supported: %s\n", change.Desc)
+		case change.Destructive:
+			_, err = fmt.Fprintf(w, "-- not applied: %s\n-- %s;\n", change.Desc, change.SQL)
+		default:
+			_, err = fmt.Fprintf(w, "%s;\n", change.SQL)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
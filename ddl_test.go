@@ -0,0 +1,88 @@
+package gosql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rushteam/gosql/schema"
+)
+
+type ddlModel struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name,size:32,index"`
+}
+
+func (m *ddlModel) TableName() string {
+	return "test"
+}
+
+func TestCreateTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("CREATE TABLE `test` \\(`id` BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT, `name` VARCHAR\\(32\\) NOT NULL, KEY `idx_test_name` \\(`name`\\)\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS "test" \("id" BIGSERIAL NOT NULL PRIMARY KEY, "name" VARCHAR\(32\) NOT NULL\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS "idx_test_name" ON "test" \("name"\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c := &PoolCluster{pools: []*dbEngine{{Db: db, Driver: "mysql"}}}
+	if err := c.CreateTable(&ddlModel{}); err != nil {
+		t.Error(err)
+	}
+	c = SetDialect(schema.Postgres{})(c)
+	if err := c.CreateTableIfNotExists(&ddlModel{}); err != nil {
+		t.Error(err)
+	}
+	c = &PoolCluster{pools: []*dbEngine{{Db: db, Driver: "unknown"}}}
+	if err := c.CreateTable(&ddlModel{}); err == nil {
+		t.Error("want an error of dialect")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAutoMigrate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	expectInspect := func() {
+		mock.ExpectQuery("SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA FROM information_schema.COLUMNS").
+			WithArgs("test").WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA"}).
+			AddRow("id", "int(11)", "NO", nil, "auto_increment"))
+		mock.ExpectQuery("SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE FROM information_schema.STATISTICS").
+			WithArgs("test").WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "NON_UNIQUE"}).
+			AddRow("PRIMARY", "id", 0))
+	}
+	expectInspect()
+	expectInspect()
+	mock.ExpectExec("ALTER TABLE `test` ADD COLUMN `name` VARCHAR\\(32\\) NOT NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX `idx_test_name` ON `test` \\(`name`\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c := &PoolCluster{pools: []*dbEngine{{Db: db, Driver: "mysql"}}}
+	var buf bytes.Buffer
+	if err := c.AutoMigrateDryRun(&buf, &ddlModel{}); err != nil {
+		t.Error(err)
+	}
+	want := "-- not applied: change type of column id from INT(11) to BIGINT\n" +
+		"-- ALTER TABLE `test` MODIFY COLUMN `id` BIGINT NOT NULL AUTO_INCREMENT;\n" +
+		"ALTER TABLE `test` ADD COLUMN `name` VARCHAR(32) NOT NULL;\n" +
+		"CREATE INDEX `idx_test_name` ON `test` (`name`);\n"
+	if buf.String() != want {
+		t.Errorf("result:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	//the type change is not applied
+	if err := c.AutoMigrate(&ddlModel{}); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
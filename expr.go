@@ -0,0 +1,171 @@
+package gosql
+
+import "reflect"
+
+//Expr sql expression with its args, it can be used in columns, order by, group by, where and update set
+type Expr interface {
+	Build() (string, []interface{})
+}
+
+//aliasExpr expression with an alias in columns
+type aliasExpr interface {
+	alias() string
+}
+
+//orderExpr expression with a direction in order by
+type orderExpr struct {
+	expr      Expr
+	direction string
+}
+
+//Column typed column for building conditions,
+//it produces the same Clause as the "[op]column" string syntax
+//eg: gosql.Where(gosql.Col("score").Gte(90))
+type Column string
+
+//Col ..
+func Col(name string) Column {
+	return Column(name)
+}
+
+//Build impl Expr
+func (c Column) Build() (string, []interface{}) {
+	return buildIdent(string(c)), nil
+}
+
+//condExpr a condition of typed column, the column is quoted as it is (not parsed from "[op]column")
+type condExpr struct {
+	column string
+	op     string
+	val    interface{}
+}
+
+//Build impl Expr
+func (c condExpr) Build() (string, []interface{}) {
+	return buildCondition(c.op, c.column, c.val)
+}
+
+func (c Column) cond(op string, val interface{}) *Clause {
+	return &Clause{key: condExpr{column: string(c), op: op, val: val}}
+}
+
+//Eq column = val, nil val means IS NULL
+func (c Column) Eq(val interface{}) *Clause {
+	if val == nil {
+		return c.IsNull()
+	}
+	return c.cond("=", val)
+}
+
+//Ne column != val, nil val means IS NOT NULL
+func (c Column) Ne(val interface{}) *Clause {
+	if val == nil {
+		return c.IsNotNull()
+	}
+	return c.cond("!=", val)
+}
+
+//Gt column > val
+func (c Column) Gt(val interface{}) *Clause {
+	return c.cond(">", val)
+}
+
+//Gte column >= val
+func (c Column) Gte(val interface{}) *Clause {
+	return c.cond(">=", val)
+}
+
+//Lt column < val
+func (c Column) Lt(val interface{}) *Clause {
+	return c.cond("<", val)
+}
+
+//Lte column <= val
+func (c Column) Lte(val interface{}) *Clause {
+	return c.cond("<=", val)
+}
+
+//In column IN (vals...), a single slice is expanded, no values is always false (1 = 0)
+func (c Column) In(vals ...interface{}) *Clause {
+	v := inValues(vals)
+	if reflect.ValueOf(v).Len() == 0 {
+		return &Clause{key: Raw("1 = 0")}
+	}
+	return c.cond("in", v)
+}
+
+//NotIn column NOT IN (vals...), a single slice is expanded, no values is always true (1 = 1)
+func (c Column) NotIn(vals ...interface{}) *Clause {
+	v := inValues(vals)
+	if reflect.ValueOf(v).Len() == 0 {
+		return &Clause{key: Raw("1 = 1")}
+	}
+	return c.cond("!in", v)
+}
+
+func inValues(vals []interface{}) interface{} {
+	if len(vals) == 1 {
+		if rv := reflect.ValueOf(vals[0]); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			return vals[0]
+		}
+	}
+	return vals
+}
+
+//Like column LIKE val
+func (c Column) Like(val interface{}) *Clause {
+	return c.cond("like", val)
+}
+
+//NotLike column NOT LIKE val
+func (c Column) NotLike(val interface{}) *Clause {
+	return c.cond("!like", val)
+}
+
+//IsNull column IS NULL
+func (c Column) IsNull() *Clause {
+	return c.cond("is", nil)
+}
+
+//IsNotNull column IS NOT NULL
+func (c Column) IsNotNull() *Clause {
+	return c.cond("!is", nil)
+}
+
+//Between column BETWEEN min AND max
+func (c Column) Between(min, max interface{}) *Clause {
+	return c.cond("between", []interface{}{min, max})
+}
+
+//NotBetween column NOT BETWEEN min AND max
+func (c Column) NotBetween(min, max interface{}) *Clause {
+	return c.cond("!between", []interface{}{min, max})
+}
+
+//And join conditions with AND
+func And(conds ...*Clause) *Clause {
+	c := &Clause{}
+	for _, cond := range conds {
+		c.Where(cond)
+	}
+	return c
+}
+
+//Or join conditions with OR
+func Or(conds ...*Clause) *Clause {
+	c := &Clause{}
+	for _, cond := range conds {
+		c.OrWhere(cond)
+	}
+	return c
+}
+
+//Not negate a condition
+func Not(cond *Clause) *Clause {
+	if cond != nil && cond.key == nil {
+		return &Clause{not: !cond.not, clause: cond.clause}
+	}
+	c := &Clause{not: true}
+	c.Where(cond)
+	return c
+}
@@ -0,0 +1,57 @@
+package gosql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnExpr(t *testing.T) {
+	result, args := SelectSQL(
+		Table("table_1"),
+		Where(Col("status").Eq(1)),
+		Where(Col("score").Gte(90)),
+		Where(Col("id").In(1, 2, 3)),
+		Where(Col("role").NotIn([]string{"a", "b"})),
+		Where(Col("name").Like("j%")),
+		Where(Col("deleted_at").IsNull()),
+		Where(Col("age").Between(18, 30)),
+		Where(Or(Col("a").Lt(1), Col("b").Ne(nil))),
+		Where(Not(And(Col("c").Gt(1), Col("d").Lte(2)))),
+		Where(Not(Col("e").Eq("x"))),
+	)
+	want := "SELECT * FROM `table_1` WHERE `status` = ? AND `score` >= ? AND `id` IN (? ,? ,?) AND `role` NOT IN (? ,?) AND `name` LIKE ? AND `deleted_at` IS NULL AND `age` BETWEEN ? AND ? AND ( `a` < ? OR `b` IS NOT NULL) AND NOT ( `c` > ? AND `d` <= ?) AND NOT ( `e` = ?)"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	wantArgs := []interface{}{1, 90, 1, 2, 3, "a", "b", "j%", 18, 30, 1, 1, 2, "x"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+}
+
+func TestColumnExprSameAsString(t *testing.T) {
+	r1, a1 := SelectSQL(Table("t"), Where("[>=]score", 90), OrWhere("[!in]id", []int{1, 2}))
+	r2, a2 := SelectSQL(Table("t"), Where(Col("score").Gte(90)), OrWhere(Col("id").NotIn([]int{1, 2})))
+	if r1 != r2 || !reflect.DeepEqual(a1, a2) {
+		t.Errorf("result: %v %v, want: %v %v", r2, a2, r1, a1)
+	}
+}
+
+func TestColumnInEmpty(t *testing.T) {
+	var ids []int
+	result, args := SelectSQL(Table("t"), Where(Col("id").In(ids)), OrWhere(Col("role").In()), Where(Col("name").NotIn([]string{})))
+	want := "SELECT * FROM `t` WHERE 1 = 0 OR 1 = 0 AND 1 = 1"
+	if result != want || len(args) != 0 {
+		t.Errorf("result: %v %v, want: %v", result, args, want)
+	}
+}
+
+func TestColumnIdent(t *testing.T) {
+	SafeMode = true
+	defer func() { SafeMode = false }()
+	result, args := SelectSQL(Table("t"), Where(Col("user$id").Eq(1)), Where(Col("名字").In("a", "b")), Where(Col("t.score").Between(1, 2)))
+	want := "SELECT * FROM `t` WHERE `user$id` = ? AND `名字` IN (? ,?) AND `t`.`score` BETWEEN ? AND ?"
+	if result != want || len(args) != 5 {
+		t.Errorf("result: %v %v, want: %v", result, args, want)
+	}
+}
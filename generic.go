@@ -0,0 +1,124 @@
+package gosql
+
+import (
+	"context"
+	"reflect"
+)
+
+//Querier a *Session or *PoolCluster for the generic API
+type Querier interface {
+	IterateContext(ctx context.Context, dst interface{}, opts ...Option) (*Rows, error)
+}
+
+//newModel allocate a model, T can be a struct or a pointer to struct
+//it returns the model and the pointer to scan into
+func newModel[T any]() (*T, interface{}) {
+	model := new(T)
+	rv := reflect.ValueOf(model).Elem()
+	if rv.Kind() == reflect.Ptr {
+		rv.Set(reflect.New(rv.Type().Elem()))
+		return model, rv.Interface()
+	}
+	return model, model
+}
+
+//scanModel scan the current row into a new model
+func scanModel[T any](rows *Rows) (T, error) {
+	model, dst := newModel[T]()
+	err := rows.Scan(dst)
+	return *model, err
+}
+
+//Get fetch a record into T, it returns ErrNoRows if not found
+//eg: user, err := gosql.Get[User](ctx, db, gosql.Where("id", 1))
+func Get[T any](ctx context.Context, q Querier, opts ...Option) (T, error) {
+	var zero T
+	_, dst := newModel[T]()
+	rows, err := q.IterateContext(ctx, dst, opts...)
+	if err != nil {
+		return zero, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, ErrNoRows
+	}
+	return scanModel[T](rows)
+}
+
+//List fetch records into []T
+//eg: users, err := gosql.List[*User](ctx, db, gosql.Where("status", 1))
+func List[T any](ctx context.Context, q Querier, opts ...Option) ([]T, error) {
+	_, dst := newModel[T]()
+	rows, err := q.IterateContext(ctx, dst, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []T
+	for rows.Next() {
+		model, err := scanModel[T](rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, model)
+	}
+	return list, rows.Err()
+}
+
+//Iterator typed iterator of records, see Iter
+type Iterator[T any] struct {
+	rows  *Rows
+	value T
+	err   error
+}
+
+//Iter fetch records one by one into T, the iterator must be closed
+//eg:
+//	it, err := gosql.Iter[User](ctx, db, gosql.Where("status", 1))
+//	defer it.Close()
+//	for it.Next() {
+//		user := it.Value()
+//	}
+//	err = it.Err()
+func Iter[T any](ctx context.Context, q Querier, opts ...Option) (*Iterator[T], error) {
+	_, dst := newModel[T]()
+	rows, err := q.IterateContext(ctx, dst, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator[T]{rows: rows}, nil
+}
+
+//Next scan the next record, it returns false when no more records or an error
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+	it.value, it.err = scanModel[T](it.rows)
+	if it.err != nil {
+		it.rows.Close()
+		return false
+	}
+	return true
+}
+
+//Value the current record
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+//Err ..
+func (it *Iterator[T]) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+//Close ..
+func (it *Iterator[T]) Close() error {
+	return it.rows.Close()
+}
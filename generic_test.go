@@ -0,0 +1,79 @@
+package gosql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGenericGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `id` = ?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `id` = ?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `id` = ?").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	c := mockCluster(db)
+	ctx := context.Background()
+	row, err := Get[rowModel](ctx, c, Where("id", 1))
+	if err != nil || row.ID != 1 || row.Name != "a" {
+		t.Errorf("result: %v, %v", row, err)
+	}
+	ptr, err := Get[*rowModel](ctx, c, Where("id", 1))
+	if err != nil || ptr == nil || ptr.Name != "a" {
+		t.Errorf("result: %v, %v", ptr, err)
+	}
+	ptr, err = Get[*rowModel](ctx, c, Where("id", 2))
+	if err != ErrNoRows || ptr != nil {
+		t.Errorf("result: %v, %v", ptr, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGenericList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `test`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b"))
+	mock.ExpectQuery("SELECT \\* FROM `test`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b")).
+		RowsWillBeClosed()
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	ctx := context.Background()
+	list, err := List[*rowModel](ctx, s)
+	if err != nil || len(list) != 2 || list[1].Name != "b" {
+		t.Errorf("result: %v, %v", list, err)
+	}
+
+	it, err := Iter[rowModel](ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var names []string
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Error(err)
+	}
+	if len(names) != 2 || names[0] != "a" {
+		t.Errorf("result: %v", names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
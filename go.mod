@@ -1,6 +1,6 @@
 module github.com/rushteam/gosql
 
-go 1.13
+go 1.18
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.0
@@ -0,0 +1,111 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+)
+
+//BeforeInsertHook called before Insert and Replace, an error aborts the statement
+type BeforeInsertHook interface {
+	BeforeInsert(ctx context.Context, s *Session) error
+}
+
+//AfterInsertHook called after Insert and Replace succeed
+type AfterInsertHook interface {
+	AfterInsert(ctx context.Context, s *Session) error
+}
+
+//BeforeUpdateHook called before Update, an error aborts the statement
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context, s *Session) error
+}
+
+//AfterUpdateHook called after Update succeed
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context, s *Session) error
+}
+
+//BeforeDeleteHook called before Delete, an error aborts the statement
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context, s *Session) error
+}
+
+//AfterDeleteHook called after Delete succeed
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context, s *Session) error
+}
+
+//AfterFetchHook called for each model fetched by Fetch, FetchAll and the rows scanned by Iterate, FetchEach and the generic API
+type AfterFetchHook interface {
+	AfterFetch(ctx context.Context, s *Session) error
+}
+
+const (
+	hookBeforeInsert uint8 = iota
+	hookAfterInsert
+	hookBeforeUpdate
+	hookAfterUpdate
+	hookBeforeDelete
+	hookAfterDelete
+	hookAfterFetch
+)
+
+//callHook call the hook of model (or each model of a slice),
+//the transaction of session is rolled back when the hook returns an error
+func (s *Session) callHook(dst interface{}, hook uint8) error {
+	var err error
+	if dstRV := reflect.Indirect(reflect.ValueOf(dst)); dstRV.Kind() == reflect.Slice {
+		for i := 0; i < dstRV.Len() && err == nil; i++ {
+			elem := dstRV.Index(i)
+			if elem.Kind() != reflect.Ptr {
+				elem = elem.Addr()
+			}
+			err = s.modelHook(elem.Interface(), hook)
+		}
+	} else {
+		err = s.modelHook(dst, hook)
+	}
+	if err != nil {
+		if _, ok := s.executor.(*sql.Tx); ok {
+			debugPrint("db: [session #%v] rollback by hook: %v", s.v, err)
+			s.Rollback()
+		}
+	}
+	return err
+}
+
+//modelHook call the hook of a model if it implements
+func (s *Session) modelHook(dst interface{}, hook uint8) error {
+	switch hook {
+	case hookBeforeInsert:
+		if m, ok := dst.(BeforeInsertHook); ok {
+			return m.BeforeInsert(s.ctx, s)
+		}
+	case hookAfterInsert:
+		if m, ok := dst.(AfterInsertHook); ok {
+			return m.AfterInsert(s.ctx, s)
+		}
+	case hookBeforeUpdate:
+		if m, ok := dst.(BeforeUpdateHook); ok {
+			return m.BeforeUpdate(s.ctx, s)
+		}
+	case hookAfterUpdate:
+		if m, ok := dst.(AfterUpdateHook); ok {
+			return m.AfterUpdate(s.ctx, s)
+		}
+	case hookBeforeDelete:
+		if m, ok := dst.(BeforeDeleteHook); ok {
+			return m.BeforeDelete(s.ctx, s)
+		}
+	case hookAfterDelete:
+		if m, ok := dst.(AfterDeleteHook); ok {
+			return m.AfterDelete(s.ctx, s)
+		}
+	case hookAfterFetch:
+		if m, ok := dst.(AfterFetchHook); ok {
+			return m.AfterFetch(s.ctx, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type hookModel struct {
+	ID    int64  `db:"id,pk"`
+	Name  string `db:"name"`
+	Upper string `db:"-"`
+	calls []string
+}
+
+func (m *hookModel) TableName() string {
+	return "test"
+}
+
+func (m *hookModel) BeforeInsert(ctx context.Context, s *Session) error {
+	m.calls = append(m.calls, "BeforeInsert")
+	if m.Name == "" {
+		m.Name = "guest"
+	}
+	return nil
+}
+
+func (m *hookModel) AfterInsert(ctx context.Context, s *Session) error {
+	m.calls = append(m.calls, "AfterInsert")
+	return nil
+}
+
+func (m *hookModel) BeforeUpdate(ctx context.Context, s *Session) error {
+	m.calls = append(m.calls, "BeforeUpdate")
+	if m.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (m *hookModel) AfterUpdate(ctx context.Context, s *Session) error {
+	m.calls = append(m.calls, "AfterUpdate")
+	return nil
+}
+
+func (m *hookModel) BeforeDelete(ctx context.Context, s *Session) error {
+	m.calls = append(m.calls, "BeforeDelete")
+	return nil
+}
+
+func (m *hookModel) AfterDelete(ctx context.Context, s *Session) error {
+	m.calls = append(m.calls, "AfterDelete")
+	return nil
+}
+
+func (m *hookModel) AfterFetch(ctx context.Context, s *Session) error {
+	m.Upper = strings.ToUpper(m.Name)
+	return nil
+}
+
+func TestSessionHooks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("INSERT INTO `test` \\(`name`\\) VALUES \\(\\?\\)").
+		WithArgs("guest").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE `test` SET `name` = \\? WHERE `id` = \\?").
+		WithArgs("tom", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `test` WHERE `id` = \\?").
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT \\* FROM `test`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "tom").AddRow(2, "jack"))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	m := &hookModel{}
+	if _, err := s.Insert(m); err != nil {
+		t.Error(err)
+	}
+	m.Name = "tom"
+	if _, err := s.Update(m); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Delete(m); err != nil {
+		t.Error(err)
+	}
+	want := "BeforeInsert,AfterInsert,BeforeUpdate,AfterUpdate,BeforeDelete,AfterDelete"
+	if calls := strings.Join(m.calls, ","); calls != want {
+		t.Errorf("hooks: %s, want %s", calls, want)
+	}
+	var list []hookModel
+	if err := s.FetchAll(&list); err != nil || len(list) != 2 || list[1].Upper != "JACK" {
+		t.Errorf("result: %v, %v", list, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionHookRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `test` \\(`name`\\) VALUES \\(\\?\\)").
+		WithArgs("guest").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Session{v: 0, executor: tx, ctx: context.TODO()}
+	if _, err := s.Insert(&hookModel{}); err != nil {
+		t.Error(err)
+	}
+	//the statement is aborted and the transaction is rolled back
+	if _, err := s.Update(&hookModel{ID: 1}); err == nil || err.Error() != "name is required" {
+		t.Errorf("want the error of hook, found %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAfterFetchHook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	for i := 0; i < 4; i++ {
+		mock.ExpectQuery("SELECT \\* FROM `test`").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "tom").AddRow(2, "jack"))
+	}
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	ctx := context.Background()
+	if m, err := Get[hookModel](ctx, s); err != nil || m.Upper != "TOM" {
+		t.Errorf("result: %v, %v", m, err)
+	}
+	if list, err := List[*hookModel](ctx, s); err != nil || len(list) != 2 || list[1].Upper != "JACK" {
+		t.Errorf("result: %v, %v", list, err)
+	}
+	it, err := Iter[*hookModel](ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it.Next() {
+		if m := it.Value(); m.Upper != strings.ToUpper(m.Name) {
+			t.Errorf("result: %v", m)
+		}
+	}
+	it.Close()
+	var uppers []string
+	err = s.FetchEach(&hookModel{}, func(row interface{}) error {
+		uppers = append(uppers, row.(*hookModel).Upper)
+		return nil
+	})
+	if err != nil || strings.Join(uppers, ",") != "TOM,JACK" {
+		t.Errorf("result: %v, %v", uppers, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
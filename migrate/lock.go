@@ -0,0 +1,84 @@
+package migrate
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+)
+
+//lock lock the migrations of db, GET_LOCK on mysql, advisory lock on postgres
+//and a row of the lock table ({table}_lock) on others
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	name := m.table + "_lock"
+	switch m.dialect.Name() {
+	case "mysql":
+		conn, err := m.db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var ok int
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(m.lockTimeout.Seconds())).Scan(&ok); err != nil || ok != 1 {
+			conn.Close()
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrLocked
+		}
+		return func() {
+			conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+			conn.Close()
+		}, nil
+	case "postgres":
+		conn, err := m.db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		key := int64(h.Sum32())
+		deadline := time.Now().Add(m.lockTimeout)
+		for {
+			var ok bool
+			if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			if ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				conn.Close()
+				return nil, ErrLocked
+			}
+			time.Sleep(time.Second)
+		}
+		return func() {
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+			conn.Close()
+		}, nil
+	}
+	//the lock row is left when the process crashed, delete it by hand
+	table := m.dialect.Quote(name)
+	if _, err := m.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+" ("+m.dialect.Quote("id")+" INTEGER NOT NULL PRIMARY KEY)"); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		_, err := m.db.ExecContext(ctx, "INSERT INTO "+table+" ("+m.dialect.Quote("id")+") VALUES (1)")
+		if err == nil {
+			break
+		}
+		//retry only when the lock row exists, otherwise it is not a conflict (eg: permission denied)
+		var n int
+		if e := m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); e != nil || n == 0 {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(time.Second)
+	}
+	return func() {
+		m.db.ExecContext(context.Background(), "DELETE FROM "+table)
+	}, nil
+}
@@ -0,0 +1,172 @@
+//Package migrate versioned migrations of schema, the migrations are SQL files or go functions,
+//the applied versions are recorded in the table schema_migrations of the primary db
+//eg:
+//
+//	m, err := migrate.New(db)
+//	err = m.LoadFS(os.DirFS("migrations"))
+//	err = m.Up(ctx)
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//Func a migration in go, it runs in the transaction of migration
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+//Migration a version of schema, Up/Down funcs are used if set, otherwise UpSQL/DownSQL
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+	Up      Func
+	Down    Func
+}
+
+//hasUp the migration can be applied
+func (m *Migration) hasUp() bool {
+	return m.Up != nil || strings.TrimSpace(m.UpSQL) != ""
+}
+
+//hasDown the migration can be rolled back
+func (m *Migration) hasDown() bool {
+	return m.Down != nil || strings.TrimSpace(m.DownSQL) != ""
+}
+
+var (
+	registered      = make(map[int64]*Migration)
+	registeredMutex sync.RWMutex
+)
+
+//Register register a go migration, it is usually called in init of the migration file
+//eg: migrate.Register(20200102030405, "add_user_status", up, down)
+func Register(version int64, name string, up, down Func) {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+	if _, ok := registered[version]; ok {
+		panic(fmt.Sprintf("migrate: duplicate version %d", version))
+	}
+	registered[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+}
+
+//fileName {version}_{name}.up.sql or {version}_{name}.down.sql
+var fileName = regexp.MustCompile(`^(\d+)_(.*)\.(up|down)\.sql$`)
+
+//LoadFS load the SQL migrations from the root of fsys, eg: os.DirFS("migrations") or an embed.FS,
+//the files are named {version}_{name}.up.sql and {version}_{name}.down.sql, eg: 0001_create_user.up.sql,
+//it is an error if a version has only a down file
+func (m *Migrator) LoadFS(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	loaded := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("migrate: invalid version of %s: %v", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return err
+		}
+		mg, ok := m.migrations[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: match[2]}
+			m.migrations[version] = mg
+		} else if mg.Name != match[2] {
+			return fmt.Errorf("migrate: duplicate version %d: %s and %s", version, mg.Name, match[2])
+		}
+		if match[3] == "up" {
+			mg.UpSQL = string(content)
+		} else {
+			mg.DownSQL = string(content)
+		}
+		loaded[version] = mg
+	}
+	for _, mg := range loaded {
+		if !mg.hasUp() {
+			return fmt.Errorf("migrate: version %d (%s) has no up migration", mg.Version, mg.Name)
+		}
+	}
+	return nil
+}
+
+//Add add migrations to the migrator
+func (m *Migrator) Add(migrations ...*Migration) error {
+	for _, mg := range migrations {
+		if _, ok := m.migrations[mg.Version]; ok {
+			return fmt.Errorf("migrate: duplicate version %d", mg.Version)
+		}
+		m.migrations[mg.Version] = mg
+	}
+	return nil
+}
+
+//sorted the migrations in the order of version
+func (m *Migrator) sorted() []*Migration {
+	list := make([]*Migration, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		list = append(list, mg)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list
+}
+
+//splitStatements split SQL by ";" at the end of line, so the ";" in a line (eg: a string) is kept,
+//the comment lines (--) are skipped,
+//a body with ";" at the end of lines (eg: $$...$$ of postgres or BEGIN...END of a trigger) is broken by the split,
+//so the lines between "-- +migrate StatementBegin" and "-- +migrate StatementEnd" are kept as one statement
+func splitStatements(content string) []string {
+	var stmts []string
+	var buf strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSuffix(strings.TrimSpace(buf.String()), ";"); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		buf.Reset()
+	}
+	block := false
+	for _, line := range strings.Split(content, "\n") {
+		switch strings.Join(strings.Fields(line), " ") {
+		case "-- +migrate StatementBegin":
+			flush()
+			block = true
+			continue
+		case "-- +migrate StatementEnd":
+			flush()
+			block = false
+			continue
+		}
+		if block {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			flush()
+		}
+	}
+	flush()
+	return stmts
+}
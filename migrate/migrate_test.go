@@ -0,0 +1,303 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rushteam/gosql/schema"
+)
+
+func TestSplitStatements(t *testing.T) {
+	content := `-- create user
+CREATE TABLE user (
+	id INT,
+	name VARCHAR(64) DEFAULT 'a;b'
+);
+INSERT INTO user (id) VALUES (1);
+
+INSERT INTO user (id) VALUES (2)`
+	want := []string{
+		"CREATE TABLE user (\n\tid INT,\n\tname VARCHAR(64) DEFAULT 'a;b'\n)",
+		"INSERT INTO user (id) VALUES (1)",
+		"INSERT INTO user (id) VALUES (2)",
+	}
+	if result := splitStatements(content); !reflect.DeepEqual(result, want) {
+		t.Errorf("result: %q\nwant: %q", result, want)
+	}
+}
+
+func TestSplitStatementsBlock(t *testing.T) {
+	content := `CREATE TABLE user (id INT, updated_at INT);
+-- +migrate StatementBegin
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+	-- the time of update
+	NEW.updated_at = 1;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+DROP TABLE tmp;`
+	want := []string{
+		"CREATE TABLE user (id INT, updated_at INT)",
+		"CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n\t-- the time of update\n\tNEW.updated_at = 1;\n\tRETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+		"DROP TABLE tmp",
+	}
+	if result := splitStatements(content); !reflect.DeepEqual(result, want) {
+		t.Errorf("result: %q\nwant: %q", result, want)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	m := NewDB(nil, schema.MySQL{})
+	err := m.LoadFS(fstest.MapFS{
+		"0002_add_status.up.sql":     {Data: []byte("ALTER TABLE user ADD status INT;")},
+		"0001_create_user.up.sql":    {Data: []byte("CREATE TABLE user (id INT);")},
+		"0001_create_user.down.sql":  {Data: []byte("DROP TABLE user;")},
+		"README.md":                  {Data: []byte("migrations")},
+		"0003_ignored/0003.up.sql":   {Data: []byte("SELECT 1;")},
+		"0004_not_a_migration.sql.b": {Data: []byte("SELECT 1;")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := m.sorted()
+	if len(list) != 2 || list[0].Version != 1 || list[0].Name != "create_user" || list[1].Version != 2 {
+		t.Fatalf("result: %+v", list)
+	}
+	if !list[0].hasDown() || list[1].hasDown() {
+		t.Errorf("result: %+v", list)
+	}
+	err = m.LoadFS(fstest.MapFS{"0002_other.up.sql": {Data: []byte("SELECT 1;")}})
+	if err == nil {
+		t.Error("the duplicate version should be an error")
+	}
+	err = NewDB(nil, schema.MySQL{}).LoadFS(fstest.MapFS{"0003_drop_user.down.sql": {Data: []byte("DROP TABLE user;")}})
+	if err == nil {
+		t.Error("the version without up should be an error")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	up := func(ctx context.Context, tx *sql.Tx) error { return nil }
+	Register(99, "registered", up, nil)
+	t.Cleanup(func() {
+		registeredMutex.Lock()
+		delete(registered, 99)
+		registeredMutex.Unlock()
+	})
+	m := NewDB(nil, schema.MySQL{})
+	if err := m.LoadFS(fstest.MapFS{"0099_registered.down.sql": {Data: []byte("SELECT 1;")}}); err != nil {
+		t.Fatal(err)
+	}
+	if !m.migrations[99].hasDown() {
+		t.Errorf("result: %+v", m.migrations[99])
+	}
+	if mg := NewDB(nil, schema.MySQL{}).migrations[99]; mg.hasDown() {
+		t.Errorf("the registered migration should not be changed: %+v", mg)
+	}
+}
+
+func newMock(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m := NewDB(db, schema.MySQL{})
+	m.Add(
+		&Migration{Version: 1, Name: "create_user", UpSQL: "CREATE TABLE user (id INT);", DownSQL: "DROP TABLE user;"},
+		&Migration{Version: 2, Name: "add_status",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "ALTER TABLE user ADD status INT")
+				return err
+			},
+			Down: func(ctx context.Context, tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "ALTER TABLE user DROP status")
+				return err
+			},
+		},
+	)
+	return m, mock
+}
+
+func expectLock(mock sqlmock.Sqlmock, applied ...int64) {
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `schema_migrations` (`version` BIGINT NOT NULL PRIMARY KEY, `name` VARCHAR(255) NOT NULL, `applied_at` BIGINT NOT NULL)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WithArgs("schema_migrations_lock", 30).
+		WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(1))
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, v := range applied {
+		rows.AddRow(v, 1577934245)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `version`, `applied_at` FROM `schema_migrations`")).WillReturnRows(rows)
+}
+
+func TestUp(t *testing.T) {
+	m, mock := newMock(t)
+	expectLock(mock, 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE user ADD status INT")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations` (`version`, `name`, `applied_at`) VALUES (?, ?, ?)")).
+		WithArgs(2, "add_status", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WithArgs("schema_migrations_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpError(t *testing.T) {
+	m, mock := newMock(t)
+	expectLock(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE user (id INT)")).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	err := m.Up(context.Background())
+	if err == nil || err.Error() != "migrate: version 1 (create_user): syntax error" {
+		t.Fatalf("result: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	m, mock := newMock(t)
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(0))
+	if err := m.Up(context.Background()); err != ErrLocked {
+		t.Fatalf("result: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDownUnknown(t *testing.T) {
+	m, mock := newMock(t)
+	expectLock(mock, 1, 2, 3)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Down(context.Background()); err == nil || err.Error() != "migrate: applied versions [3] have no migration" {
+		t.Errorf("result: %v", err)
+	}
+	expectLock(mock, 1, 2, 3)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Goto(context.Background(), 1); err == nil {
+		t.Error("want an error of unknown version")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLockTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	m := NewDB(db, schema.SQLite{}, SetLockTimeout(0))
+	//locked by another instance
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "schema_migrations_lock"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "schema_migrations_lock"`)).WillReturnError(errors.New("UNIQUE constraint failed"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM "schema_migrations_lock"`)).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	if _, err := m.lock(context.Background()); err != ErrLocked {
+		t.Errorf("want ErrLocked, found %v", err)
+	}
+	//not a conflict
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "schema_migrations_lock"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "schema_migrations_lock"`)).WillReturnError(errors.New("permission denied"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM "schema_migrations_lock"`)).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(0))
+	if _, err := m.lock(context.Background()); err == nil || err.Error() != "permission denied" {
+		t.Errorf("result: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDown(t *testing.T) {
+	m, mock := newMock(t)
+	expectLock(mock, 1, 2)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE user DROP status")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `schema_migrations` WHERE `version` = ?")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Down(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGoto(t *testing.T) {
+	m, mock := newMock(t)
+	if err := m.Goto(context.Background(), 3); err == nil {
+		t.Error("the unknown version should be an error")
+	}
+	expectLock(mock, 1, 2)
+	for _, stmt := range []string{"ALTER TABLE user DROP status", "DROP TABLE user"} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(stmt)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `schema_migrations`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Goto(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	expectLock(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE user (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations`")).WithArgs(1, "create_user", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Goto(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	m, mock := newMock(t)
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `version`, `applied_at` FROM `schema_migrations`")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, 1577934245).AddRow(5, 1577934245))
+	list, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("result: %+v", list)
+	}
+	if !list[0].Applied || list[0].AppliedAt.Unix() != 1577934245 || list[1].Applied || list[1].Name != "add_status" ||
+		list[2].Version != 5 || !list[2].Applied || list[2].Name != "" {
+		t.Errorf("result: %+v", list)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	m := NewDB(nil, schema.Postgres{}, SetTable("migrations"))
+	if result := m.rebind("INSERT INTO t (a, b) VALUES (?, ?)"); result != "INSERT INTO t (a, b) VALUES ($1, $2)" {
+		t.Errorf("result: %s", result)
+	}
+	if m.table != "migrations" {
+		t.Errorf("result: %s", m.table)
+	}
+}
@@ -0,0 +1,319 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rushteam/gosql"
+	"github.com/rushteam/gosql/schema"
+)
+
+//DefaultTable the table of applied versions
+const DefaultTable = "schema_migrations"
+
+//ErrLocked another instance is migrating
+var ErrLocked = errors.New("migrate: locked by another instance")
+
+//Migrator run the migrations on the primary db of a cluster
+type Migrator struct {
+	db          *sql.DB
+	dialect     schema.Dialect
+	table       string
+	lockTimeout time.Duration
+	migrations  map[int64]*Migration
+}
+
+//Option ..
+type Option func(m *Migrator) *Migrator
+
+//SetTable set the table of applied versions, default schema_migrations
+func SetTable(name string) Option {
+	return func(m *Migrator) *Migrator {
+		m.table = name
+		return m
+	}
+}
+
+//SetLockTimeout how long to wait for the lock of another instance, default 30s
+func SetLockTimeout(d time.Duration) Option {
+	return func(m *Migrator) *Migrator {
+		m.lockTimeout = d
+		return m
+	}
+}
+
+//New a migrator on the primary db of cluster, the migrations registered by Register are added
+func New(c *gosql.PoolCluster, opts ...Option) (*Migrator, error) {
+	d, err := c.Dialect()
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.Primary()
+	if err != nil {
+		return nil, err
+	}
+	executor, err := s.Executor()
+	if err != nil {
+		return nil, err
+	}
+	db, ok := executor.(*sql.DB)
+	if !ok {
+		return nil, fmt.Errorf("migrate: the primary is not a *sql.DB, found %T", executor)
+	}
+	return NewDB(db, d, opts...), nil
+}
+
+//NewDB a migrator on db
+func NewDB(db *sql.DB, d schema.Dialect, opts ...Option) *Migrator {
+	m := &Migrator{
+		db:          db,
+		dialect:     d,
+		table:       DefaultTable,
+		lockTimeout: 30 * time.Second,
+		migrations:  make(map[int64]*Migration),
+	}
+	registeredMutex.RLock()
+	for version, mg := range registered {
+		//copy, so LoadFS does not change the registered migration
+		c := *mg
+		m.migrations[version] = &c
+	}
+	registeredMutex.RUnlock()
+	for _, opt := range opts {
+		m = opt(m)
+	}
+	return m
+}
+
+//Status the status of a migration
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+//rebind replace the placeholders ? by the placeholders of dialect (eg: $n for postgres)
+func (m *Migrator) rebind(query string) string {
+	var buf strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			buf.WriteString(m.dialect.Placeholder(n))
+			continue
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+//init create the table of applied versions
+func (m *Migrator) init(ctx context.Context) error {
+	d := m.dialect
+	t := &schema.Table{
+		Name: m.table,
+		Columns: []*schema.Column{
+			{Name: "version", Type: d.DataType(reflect.TypeOf(int64(0)), 0)},
+			{Name: "name", Type: d.DataType(reflect.TypeOf(""), 255)},
+			{Name: "applied_at", Type: d.DataType(reflect.TypeOf(int64(0)), 0)},
+		},
+		PrimaryKeys: []string{"version"},
+	}
+	for _, stmt := range schema.CreateTable(d, t, true) {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//applied the applied versions and the unix time
+func (m *Migrator) applied(ctx context.Context) (map[int64]int64, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT "+m.dialect.Quote("version")+", "+m.dialect.Quote("applied_at")+" FROM "+m.dialect.Quote(m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]int64)
+	for rows.Next() {
+		var version, at int64
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+//Status the status of all migrations, the applied versions without migration are listed with an empty name
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []Status
+	for _, mg := range m.sorted() {
+		at, ok := applied[mg.Version]
+		st := Status{Version: mg.Version, Name: mg.Name, Applied: ok}
+		if ok {
+			st.AppliedAt = time.Unix(at, 0)
+		}
+		list = append(list, st)
+		delete(applied, mg.Version)
+	}
+	for version, at := range applied {
+		list = append(list, Status{Version: version, Applied: true, AppliedAt: time.Unix(at, 0)})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+//Up apply all pending migrations in the order of version
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.migrate(ctx, func(list []*Migration, applied map[int64]int64) error {
+		for _, mg := range list {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, mg, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//Down roll back the last applied migration, it is an error if the last applied version has no migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.migrate(ctx, func(list []*Migration, applied map[int64]int64) error {
+		var last int64
+		for v := range applied {
+			if v > last {
+				last = v
+			}
+		}
+		if err := m.checkApplied(applied, last-1); err != nil {
+			return err
+		}
+		for i := len(list) - 1; i >= 0; i-- {
+			if _, ok := applied[list[i].Version]; ok {
+				return m.run(ctx, list[i], false)
+			}
+		}
+		return nil
+	})
+}
+
+//Goto migrate up or down to the version, the migrations after it are rolled back
+//and the pending ones up to it are applied, 0 rolls back all,
+//it is an error if an applied version after it has no migration
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if _, ok := m.migrations[version]; !ok && version != 0 {
+		return fmt.Errorf("migrate: version %d not found", version)
+	}
+	return m.migrate(ctx, func(list []*Migration, applied map[int64]int64) error {
+		if err := m.checkApplied(applied, version); err != nil {
+			return err
+		}
+		for i := len(list) - 1; i >= 0; i-- {
+			if _, ok := applied[list[i].Version]; ok && list[i].Version > version {
+				if err := m.run(ctx, list[i], false); err != nil {
+					return err
+				}
+			}
+		}
+		for _, mg := range list {
+			if _, ok := applied[mg.Version]; !ok && mg.Version <= version {
+				if err := m.run(ctx, mg, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+//checkApplied the applied versions after version have migrations, so they are rolled back in order
+func (m *Migrator) checkApplied(applied map[int64]int64, version int64) error {
+	var unknown []int64
+	for v := range applied {
+		if _, ok := m.migrations[v]; !ok && v > version {
+			unknown = append(unknown, v)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+		return fmt.Errorf("migrate: applied versions %v have no migration", unknown)
+	}
+	return nil
+}
+
+//migrate run fn with the lock and the applied versions
+func (m *Migrator) migrate(ctx context.Context, fn func(list []*Migration, applied map[int64]int64) error) error {
+	if err := m.init(ctx); err != nil {
+		return err
+	}
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	return fn(m.sorted(), applied)
+}
+
+//run apply (up) or roll back a migration in a transaction with its record,
+//NOTE: DDL is committed implicitly on mysql
+func (m *Migrator) run(ctx context.Context, mg *Migration, up bool) error {
+	if up && !mg.hasUp() {
+		return fmt.Errorf("migrate: version %d (%s) has no up migration", mg.Version, mg.Name)
+	}
+	if !up && !mg.hasDown() {
+		return fmt.Errorf("migrate: version %d (%s) can not be rolled back", mg.Version, mg.Name)
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	fn, content := mg.Up, mg.UpSQL
+	if !up {
+		fn, content = mg.Down, mg.DownSQL
+	}
+	if fn != nil {
+		err = fn(ctx, tx)
+	} else {
+		for _, stmt := range splitStatements(content) {
+			if _, err = tx.ExecContext(ctx, stmt); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		if up {
+			_, err = tx.ExecContext(ctx, m.rebind("INSERT INTO "+m.dialect.Quote(m.table)+" ("+m.dialect.Quote("version")+", "+
+				m.dialect.Quote("name")+", "+m.dialect.Quote("applied_at")+") VALUES (?, ?, ?)"), mg.Version, mg.Name, time.Now().Unix())
+		} else {
+			_, err = tx.ExecContext(ctx, m.rebind("DELETE FROM "+m.dialect.Quote(m.table)+" WHERE "+m.dialect.Quote("version")+" = ?"), mg.Version)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migrate: version %d (%s): %w", mg.Version, mg.Name, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,246 @@
+package gosql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rushteam/gosql/scanner"
+	"github.com/rushteam/gosql/schema"
+)
+
+//Named rewrite named parameters (":name" or "@name") to positional placeholders "?" (the mysql style as the builder),
+//the parameters in quoted strings and comments ("--" and "/* */") are kept
+//the values are bind from a map[string]interface{} or a tagged struct (use the column names of scanner.DefaultMapper,
+//the named methods of Session use the naming and the dialect of cluster),
+//slice values are expanded for IN lists
+//eg: Named("select * from user where id in (:ids) and status = @status", map[string]interface{}{"ids": []int{1, 2}, "status": 1})
+//sql: select * from user where id in (?, ?) and status = ?
+//NOTE: the named parameters of a raw condition (eg: Where("[#]age > :age", map[string]interface{}{"age": 18}))
+//are bound when the sql is built, they are "?" as the builder
+func Named(query string, arg interface{}) (string, []interface{}, error) {
+	return named(scanner.DefaultMapper, nil, query, arg)
+}
+
+//NamedDialect rewrite named parameters to the positional placeholders of dialect, see Named
+//eg: NamedDialect(schema.Postgres{}, "select * from user where id in (:ids)", map[string]interface{}{"ids": []int{1, 2}})
+//sql: select * from user where id in ($1, $2)
+func NamedDialect(d schema.Dialect, query string, arg interface{}) (string, []interface{}, error) {
+	return named(scanner.DefaultMapper, d, query, arg)
+}
+
+//named bind the struct by the columns of m, the placeholders are "?" if d is nil
+func named(m *scanner.Mapper, d schema.Dialect, query string, arg interface{}) (string, []interface{}, error) {
+	params, err := namedParams(m, arg)
+	if err != nil {
+		return "", nil, err
+	}
+	var buffer bytes.Buffer
+	var args []interface{}
+	n := len(query)
+	for i := 0; i < n; i++ {
+		ch := query[i]
+		switch ch {
+		case '\'', '"', '`':
+			//skip quoted string
+			j := i + 1
+			for ; j < n; j++ {
+				if query[j] == '\\' && ch != '`' {
+					j++
+					continue
+				}
+				if query[j] == ch {
+					break
+				}
+			}
+			if j >= n {
+				j = n - 1
+			}
+			buffer.WriteString(query[i : j+1])
+			i = j
+		case '-', '/':
+			//skip comment: "-- to the end of line" and "/* */"
+			end := ""
+			if ch == '-' && i+1 < n && query[i+1] == '-' {
+				end = "\n"
+			} else if ch == '/' && i+1 < n && query[i+1] == '*' {
+				end = "*/"
+			}
+			if end == "" {
+				buffer.WriteByte(ch)
+				continue
+			}
+			j := strings.Index(query[i+2:], end)
+			if j < 0 {
+				j = n
+			} else {
+				j = i + 2 + j + len(end)
+			}
+			buffer.WriteString(query[i:j])
+			i = j - 1
+		case ':', '@':
+			//"::" is a type cast and "@@" is a system variable
+			if i+1 < n && query[i+1] == ch {
+				buffer.WriteString(query[i : i+2])
+				i++
+				continue
+			}
+			j := i + 1
+			for j < n && isNameChar(query[j], j == i+1) {
+				j++
+			}
+			if j == i+1 {
+				buffer.WriteByte(ch)
+				continue
+			}
+			name := query[i+1 : j]
+			val, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("gosql: named parameter [%s] not found", name)
+			}
+			if vals, ok := expandSlice(val); ok {
+				if len(vals) == 0 {
+					return "", nil, fmt.Errorf("gosql: named parameter [%s] is an empty slice", name)
+				}
+				for k, v := range vals {
+					if k > 0 {
+						buffer.WriteString(", ")
+					}
+					args = append(args, v)
+					buffer.WriteString(placeholder(d, len(args)))
+				}
+			} else {
+				args = append(args, val)
+				buffer.WriteString(placeholder(d, len(args)))
+			}
+			i = j - 1
+		default:
+			buffer.WriteByte(ch)
+		}
+	}
+	return buffer.String(), args, nil
+}
+
+//placeholder the i-th placeholder of dialect, "?" if d is nil
+func placeholder(d schema.Dialect, i int) string {
+	if d == nil {
+		return "?"
+	}
+	return d.Placeholder(i)
+}
+
+func isNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+//expandSlice expand a slice value to args, []byte and driver.Valuer are not expanded
+func expandSlice(val interface{}) ([]interface{}, bool) {
+	if _, ok := val.(driver.Valuer); ok {
+		return nil, false
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	vals := make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		vals[i] = rv.Index(i).Interface()
+	}
+	return vals, true
+}
+
+//isNamedArg check the value can bind named parameters
+func isNamedArg(arg interface{}) bool {
+	switch arg.(type) {
+	case map[string]interface{}:
+		return true
+	case time.Time, *time.Time, driver.Valuer:
+		return false
+	}
+	return isFilterStruct(arg)
+}
+
+func namedParams(m *scanner.Mapper, arg interface{}) (map[string]interface{}, error) {
+	switch v := arg.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case nil:
+		return map[string]interface{}{}, nil
+	}
+	if !isFilterStruct(arg) {
+		return nil, fmt.Errorf("gosql: named parameters must be a map[string]interface{} or struct, found %T", arg)
+	}
+	return m.ResolveStructValue(arg)
+}
+
+//NamedQueryContext query with named parameters
+func (s *Session) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	query, args, err := named(s.Mapper(), s.dialect, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return s.QueryContext(ctx, query, args...)
+}
+
+//NamedQuery query with named parameters
+func (s *Session) NamedQuery(query string, arg interface{}) (*sql.Rows, error) {
+	return s.NamedQueryContext(s.ctx, query, arg)
+}
+
+//NamedExecContext exec with named parameters
+func (s *Session) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	query, args, err := named(s.Mapper(), s.dialect, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return s.ExecContext(ctx, query, args...)
+}
+
+//NamedExec exec with named parameters
+func (s *Session) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return s.NamedExecContext(s.ctx, query, arg)
+}
+
+//NamedQueryContext query with named parameters on replica
+func (c *PoolCluster) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	s, err := c.Replica()
+	if err != nil {
+		return nil, err
+	}
+	return s.NamedQueryContext(ctx, query, arg)
+}
+
+//NamedQuery query with named parameters on replica
+func (c *PoolCluster) NamedQuery(query string, arg interface{}) (*sql.Rows, error) {
+	s, err := c.Replica()
+	if err != nil {
+		return nil, err
+	}
+	return s.NamedQuery(query, arg)
+}
+
+//NamedExecContext exec with named parameters on primary
+func (c *PoolCluster) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	s, err := c.Primary()
+	if err != nil {
+		return nil, err
+	}
+	return s.NamedExecContext(ctx, query, arg)
+}
+
+//NamedExec exec with named parameters on primary
+func (c *PoolCluster) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	s, err := c.Primary()
+	if err != nil {
+		return nil, err
+	}
+	return s.NamedExec(query, arg)
+}
@@ -0,0 +1,161 @@
+package gosql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rushteam/gosql/schema"
+)
+
+func TestNamed(t *testing.T) {
+	query, args, err := Named(
+		"SELECT * FROM user WHERE id IN (:ids) AND status = @status AND name != ':ids' AND ts::date = :day AND @@autocommit = 1 AND age > :status",
+		map[string]interface{}{"ids": []int{1, 2, 3}, "status": 1, "day": "2020-01-01"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM user WHERE id IN (?, ?, ?) AND status = ? AND name != ':ids' AND ts::date = ? AND @@autocommit = 1 AND age > ?"
+	if query != want {
+		t.Errorf("result: %v, want: %v", query, want)
+	}
+	wantArgs := []interface{}{1, 2, 3, 1, "2020-01-01", 1}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+}
+
+func TestNamedComment(t *testing.T) {
+	query, args, err := Named("SELECT * FROM user -- don't :skip\nWHERE id = :id /* it's :id */ AND a = 1 - :id / 2 --:end", map[string]interface{}{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM user -- don't :skip\nWHERE id = ? /* it's :id */ AND a = 1 - ? / 2 --:end"
+	if query != want {
+		t.Errorf("result: %v, want: %v", query, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 1}) {
+		t.Errorf("result: %v", args)
+	}
+}
+
+func TestNamedStruct(t *testing.T) {
+	type Param struct {
+		UserName string `db:"name"`
+		Age      int
+	}
+	query, args, err := Named("SELECT * FROM user WHERE name = :name AND age = :age", &Param{"jack", 18})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM user WHERE name = ? AND age = ?"
+	if query != want {
+		t.Errorf("result: %v, want: %v", query, want)
+	}
+	wantArgs := []interface{}{"jack", 18}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+}
+
+func TestNamedErr(t *testing.T) {
+	if _, _, err := Named("SELECT :a", map[string]interface{}{}); err == nil {
+		t.Error("want a not found error")
+	}
+	if _, _, err := Named("SELECT :a", map[string]interface{}{"a": []int{}}); err == nil {
+		t.Error("want an empty slice error")
+	}
+	if _, _, err := Named("SELECT :a", 1); err == nil {
+		t.Error("want a type error")
+	}
+}
+
+func TestNamedDialect(t *testing.T) {
+	query, args, err := NamedDialect(schema.Postgres{}, "SELECT * FROM user WHERE id IN (:ids) AND status = :status AND ts::date = :day", map[string]interface{}{"ids": []int{1, 2}, "status": 1, "day": "2020-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM user WHERE id IN ($1, $2) AND status = $3 AND ts::date = $4"
+	if query != want {
+		t.Errorf("result: %v, want: %v", query, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 2, 1, "2020-01-01"}) {
+		t.Errorf("result: %v", args)
+	}
+	query, _, err = NamedDialect(schema.SQLite{}, "SELECT * FROM user WHERE id = :id", map[string]interface{}{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE id = ?" {
+		t.Errorf("result: %v", query)
+	}
+}
+
+func TestNamedWhere(t *testing.T) {
+	result, args := SelectSQL(
+		Table("user"),
+		Where("[#]age > :age AND role IN (:roles)", map[string]interface{}{"age": 18, "roles": []string{"a", "b"}}),
+	)
+	want := "SELECT * FROM `user` WHERE age > ? AND role IN (?, ?)"
+	if result != want {
+		t.Errorf("result: %v, want: %v", result, want)
+	}
+	wantArgs := []interface{}{18, "a", "b"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("result: %v, want: %v", args, wantArgs)
+	}
+}
+
+func TestNamedWhereNotFound(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("want panic for a parameter not found")
+		}
+	}()
+	SelectSQL(Table("user"), Where("[#]age > :age", map[string]interface{}{}))
+}
+
+func TestSessionNamedExec(t *testing.T) {
+	Debug = true
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("UPDATE test SET name = \\? WHERE id IN \\(\\?, \\?\\)").WithArgs("tom", 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT (.+) FROM test WHERE id = \\?").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	_, err = s.NamedExec("UPDATE test SET name = :name WHERE id IN (:ids)", map[string]interface{}{"name": "tom", "ids": []int{1, 2}})
+	if err != nil {
+		t.Error(err)
+	}
+	rows, err := s.NamedQuery("SELECT * FROM test WHERE id = :id", map[string]interface{}{"id": 1})
+	if err != nil {
+		t.Error(err)
+	} else {
+		rows.Close()
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionNamedDialect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("UPDATE test SET name = \\$1 WHERE id IN \\(\\$2, \\$3\\)").WithArgs("tom", 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	c := SetDialect(schema.Postgres{})(mockCluster(db))
+	if _, err = c.NamedExec("UPDATE test SET name = :name WHERE id IN (:ids)", map[string]interface{}{"name": "tom", "ids": []int{1, 2}}); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"sync/atomic"
 	"time"
+
+	"github.com/rushteam/gosql/scanner"
+	"github.com/rushteam/gosql/schema"
 )
 
 //DbOption ..
@@ -39,6 +42,8 @@ type PoolCluster struct {
 	vs           uint64
 	pools        []*dbEngine
 	forcePrimary bool
+	mapper       *scanner.Mapper
+	dialect      schema.Dialect
 }
 
 // PoolClusterOpts ..
@@ -50,7 +55,8 @@ func (c *PoolCluster) newSession(primary bool) (*Session, error) {
 	if n == 0 {
 		return nil, errors.New("not found db config")
 	}
-	s := &Session{v: atomic.AddUint64(&(c.vs), 1), ctx: context.Background()}
+	s := &Session{v: atomic.AddUint64(&(c.vs), 1), ctx: context.Background(), mapper: c.mapper}
+	s.dialect, _ = c.Dialect()
 	var dbx *dbEngine
 	if primary || c.forcePrimary {
 		//select primary db
@@ -163,6 +169,24 @@ func (c *PoolCluster) FetchAll(dst interface{}, opts ...Option) error {
 	return s.FetchAll(dst, opts...)
 }
 
+//FetchByKey fetch a record by primary key
+func (c *PoolCluster) FetchByKey(dst interface{}, keys ...interface{}) error {
+	s, err := c.Replica()
+	if err != nil {
+		return err
+	}
+	return s.FetchByKey(dst, keys...)
+}
+
+//Pluck fetch a column into a slice
+func (c *PoolCluster) Pluck(column string, dst interface{}, opts ...Option) error {
+	s, err := c.Replica()
+	if err != nil {
+		return err
+	}
+	return s.Pluck(column, dst, opts...)
+}
+
 //Update update from model
 func (c *PoolCluster) Update(dst interface{}, opts ...Option) (Result, error) {
 	s, err := c.Primary()
@@ -221,6 +245,34 @@ func AddDb(driver, dsn string, opts ...DbOption) PoolClusterOpts {
 	}
 }
 
+//SetNaming set the naming strategy of tables and columns for models of the cluster
+//eg: gosql.SetNaming(scanner.Naming{TablePrefix: "t_", SnakeTable: true, PluralTable: true})
+func SetNaming(naming scanner.NamingStrategy) PoolClusterOpts {
+	return func(p *PoolCluster) *PoolCluster {
+		p.mapper = scanner.NewMapper(naming).Strict(p.mapper != nil && p.mapper.IsStrict())
+		return p
+	}
+}
+
+//SetStrict scan results in strict mode for the cluster, see Session.Strict
+func SetStrict(strict bool) PoolClusterOpts {
+	return func(p *PoolCluster) *PoolCluster {
+		if p.mapper == nil {
+			p.mapper = scanner.DefaultMapper
+		}
+		p.mapper = p.mapper.Strict(strict)
+		return p
+	}
+}
+
+//SetDialect set the dialect of DDL for the cluster, by default it is selected by the driver of primary db
+func SetDialect(d schema.Dialect) PoolClusterOpts {
+	return func(p *PoolCluster) *PoolCluster {
+		p.dialect = d
+		return p
+	}
+}
+
 //SetConnMaxLifetime ..
 func SetConnMaxLifetime(d time.Duration) DbOption {
 	return func(db *sql.DB) *sql.DB {
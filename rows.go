@@ -0,0 +1,161 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/rushteam/gosql/scanner"
+)
+
+//Rows iterator of query results, the rows are scanned one by one by the scanner,
+//it is closed when Next returns false, but Close should be deferred for an early return
+//eg:
+//
+//	rows, err := db.Iterate(&User{}, gosql.Where("status", 1))
+//	defer rows.Close()
+//	for rows.Next() {
+//		u := &User{}
+//		err = rows.Scan(u)
+//	}
+//	err = rows.Err()
+type Rows struct {
+	ctx     context.Context
+	rows    *sql.Rows
+	mapper  *scanner.Mapper
+	session *Session
+	err     error
+}
+
+//Next prepare the next row, it returns false when no more rows, an error or the context is done
+func (r *Rows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	if err := r.ctx.Err(); err != nil {
+		r.err = err
+		r.Close()
+		return false
+	}
+	if !r.rows.Next() {
+		r.Close()
+		return false
+	}
+	return true
+}
+
+//Scan the current row into a struct, map or scalar, the AfterFetch hook of a model is called
+func (r *Rows) Scan(dst interface{}) error {
+	if err := r.mapper.ScanCurrent(r.rows, dst); err != nil {
+		return err
+	}
+	return r.session.callHook(dst, hookAfterFetch)
+}
+
+//Columns ..
+func (r *Rows) Columns() ([]string, error) {
+	return r.rows.Columns()
+}
+
+//Err the error of iteration
+func (r *Rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+//Close ..
+func (r *Rows) Close() error {
+	return r.rows.Close()
+}
+
+//IterateContext query records of model and return an iterator
+func (s *Session) IterateContext(ctx context.Context, dst interface{}, opts ...Option) (*Rows, error) {
+	debugPrint("db: [session #%v] Iterate()", s.v)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
+	if err != nil {
+		return nil, err
+	}
+	sql, args, err := s.selectModelSQL(dstStruct, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{ctx: ctx, rows: rows, mapper: s.Mapper(), session: s}, nil
+}
+
+//Iterate query records of model and return an iterator
+func (s *Session) Iterate(dst interface{}, opts ...Option) (*Rows, error) {
+	return s.IterateContext(s.ctx, dst, opts...)
+}
+
+//FetchEachContext fetch records one by one, fn is called with a new model (same type of dst) for each row,
+//an error returned by fn stops the iteration
+//eg: db.FetchEach(&User{}, func(row interface{}) error { u := row.(*User) }, gosql.Where("status", 1))
+func (s *Session) FetchEachContext(ctx context.Context, dst interface{}, fn func(row interface{}) error, opts ...Option) error {
+	dstRT := reflect.TypeOf(dst)
+	if dstRT == nil || dstRT.Kind() != reflect.Ptr || dstRT.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("gosql: FetchEach expects a pointer to struct, found %T", dst)
+	}
+	rows, err := s.IterateContext(ctx, dst, opts...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row := reflect.New(dstRT.Elem()).Interface()
+		if err := rows.Scan(row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+//FetchEach fetch records one by one
+func (s *Session) FetchEach(dst interface{}, fn func(row interface{}) error, opts ...Option) error {
+	return s.FetchEachContext(s.ctx, dst, fn, opts...)
+}
+
+//IterateContext query records on replica and return an iterator
+func (c *PoolCluster) IterateContext(ctx context.Context, dst interface{}, opts ...Option) (*Rows, error) {
+	s, err := c.Replica()
+	if err != nil {
+		return nil, err
+	}
+	return s.IterateContext(ctx, dst, opts...)
+}
+
+//Iterate query records on replica and return an iterator
+func (c *PoolCluster) Iterate(dst interface{}, opts ...Option) (*Rows, error) {
+	s, err := c.Replica()
+	if err != nil {
+		return nil, err
+	}
+	return s.Iterate(dst, opts...)
+}
+
+//FetchEachContext fetch records one by one on replica
+func (c *PoolCluster) FetchEachContext(ctx context.Context, dst interface{}, fn func(row interface{}) error, opts ...Option) error {
+	s, err := c.Replica()
+	if err != nil {
+		return err
+	}
+	return s.FetchEachContext(ctx, dst, fn, opts...)
+}
+
+//FetchEach fetch records one by one on replica
+func (c *PoolCluster) FetchEach(dst interface{}, fn func(row interface{}) error, opts ...Option) error {
+	s, err := c.Replica()
+	if err != nil {
+		return err
+	}
+	return s.FetchEach(dst, fn, opts...)
+}
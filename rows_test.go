@@ -0,0 +1,122 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type rowModel struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func (r *rowModel) TableName() string {
+	return "test"
+}
+
+func TestSessionFetchEach(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `status` = ?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b").AddRow(3, "c")).
+		RowsWillBeClosed()
+
+	c := mockCluster(db)
+	var list []*rowModel
+	err = c.FetchEach(&rowModel{}, func(row interface{}) error {
+		list = append(list, row.(*rowModel))
+		return nil
+	}, Where("status", 1))
+	if err != nil {
+		t.Error(err)
+	}
+	if len(list) != 3 || list[0].Name != "a" || list[2].ID != 3 {
+		t.Errorf("result: %v", list)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionFetchEachStop(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `test`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b")).
+		RowsWillBeClosed()
+	mock.ExpectQuery("SELECT \\* FROM `test`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b")).
+		RowsWillBeClosed()
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	stop := errors.New("stop")
+	n := 0
+	err = s.FetchEach(&rowModel{}, func(row interface{}) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("result: %v, %d", err, n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n = 0
+	err = s.FetchEachContext(ctx, &rowModel{}, func(row interface{}) error {
+		n++
+		cancel()
+		return nil
+	})
+	if err != context.Canceled || n != 1 {
+		t.Errorf("result: %v, %d", err, n)
+	}
+	if err := s.FetchEach(rowModel{}, nil); err == nil {
+		t.Error("want an error of non-pointer model")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionIterate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT `id` FROM `test`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2)).
+		RowsWillBeClosed()
+
+	c := mockCluster(db)
+	rows, err := c.Iterate(&rowModel{}, Columns("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Error(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("result: %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type benchModel struct {
+	ID        int64  `db:"id,pk"`
+	Name      string `db:"name"`
+	Email     string `db:"email"`
+	Score     int    `db:"score"`
+	Status    int8   `db:"status"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func benchRows(b *testing.B) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	return db, mock
+}
+
+func benchQuery(b *testing.B, db *sql.DB, mock sqlmock.Sqlmock, n int) *sql.Rows {
+	now := time.Now()
+	mrows := sqlmock.NewRows([]string{"id", "name", "email", "score", "status", "created_at", "updated_at"})
+	for i := 0; i < n; i++ {
+		mrows.AddRow(int64(i), "tom", "tom@example.com", 90, 1, now, now)
+	}
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(mrows)
+	rows, err := db.Query("select * from test")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return rows
+}
+
+func BenchmarkScanAll10k(b *testing.B) {
+	db, mock := benchRows(b)
+	defer db.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rows := benchQuery(b, db, mock, 10000)
+		var dst []*benchModel
+		b.StartTimer()
+		if err := ScanAll(rows, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScan10k(b *testing.B) {
+	db, mock := benchRows(b)
+	defer db.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rows := benchQuery(b, db, mock, 10000)
+		b.StartTimer()
+		for {
+			dst := &benchModel{}
+			if err := Scan(rows, dst); err != nil {
+				if err == sql.ErrNoRows {
+					break
+				}
+				b.Fatal(err)
+			}
+		}
+		rows.Close()
+	}
+}
+
+func TestResolveConcurrent(t *testing.T) {
+	m := NewMapper(Naming{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := m.ResolveModelStruct(&benchModel{}); err != nil {
+					t.Error(err)
+				}
+				if _, err := m.Targets(&benchModel{}, []string{"id", "name", "unknown"}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//Codec transform a field, Encode before write and Decode after read
+//select by tag, eg: `db:"tags,codec:csv"`
+type Codec interface {
+	//Encode field value to column value
+	Encode(value interface{}) (interface{}, error)
+	//Decode column value to dst, dst is a pointer to the field
+	Decode(value interface{}, dst interface{}) error
+}
+
+var (
+	codecs      = make(map[string]Codec)
+	codecsMutex sync.RWMutex
+)
+
+//RegisterCodec register a codec by name
+func RegisterCodec(name string, codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[strings.ToLower(name)] = codec
+}
+
+//GetCodec get a codec by name
+func GetCodec(name string) (Codec, bool) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	codec, ok := codecs[strings.ToLower(name)]
+	return codec, ok
+}
+
+func init() {
+	RegisterCodec("csv", csvCodec{})
+	RegisterCodec("json", jsonCodec{})
+}
+
+//jsonCodec struct, map and slice <=> json, NULL <=> nil/zero value
+//`db:"meta,json"` is short for `db:"meta,codec:json"`
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(value interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (jsonCodec) Decode(value interface{}, dst interface{}) error {
+	dstRV := reflect.ValueOf(dst)
+	if dstRV.Kind() != reflect.Ptr || dstRV.IsNil() {
+		return fmt.Errorf("scanner: json codec expects a pointer, found %T", dst)
+	}
+	//reset to zero value, NULL keeps it
+	dstRV.Elem().Set(reflect.Zero(dstRV.Elem().Type()))
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("scanner: json codec can not decode %T", value)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dst)
+}
+
+//csvCodec []string <=> "a,b,c"
+type csvCodec struct{}
+
+func (csvCodec) Encode(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ","), nil
+	case *[]string:
+		if v == nil {
+			return nil, nil
+		}
+		return strings.Join(*v, ","), nil
+	}
+	return nil, fmt.Errorf("scanner: csv codec expects []string, found %T", value)
+}
+
+func (csvCodec) Decode(value interface{}, dst interface{}) error {
+	var list []string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		if v != "" {
+			list = strings.Split(v, ",")
+		}
+	case []byte:
+		if len(v) > 0 {
+			list = strings.Split(string(v), ",")
+		}
+	default:
+		return fmt.Errorf("scanner: csv codec can not decode %T", value)
+	}
+	switch d := dst.(type) {
+	case *[]string:
+		*d = list
+	case **[]string:
+		if value == nil {
+			*d = nil
+		} else {
+			*d = &list
+		}
+	default:
+		return fmt.Errorf("scanner: csv codec expects *[]string, found %T", dst)
+	}
+	return nil
+}
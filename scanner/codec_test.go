@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Encode(value interface{}) (interface{}, error) {
+	return strings.ToUpper(value.(string)), nil
+}
+
+func (upperCodec) Decode(value interface{}, dst interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		*(dst.(*string)) = strings.ToLower(string(v))
+	case string:
+		*(dst.(*string)) = strings.ToLower(v)
+	}
+	return nil
+}
+
+//money a type implements sql.Scanner and driver.Valuer
+type money int64
+
+func (m *money) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("money: can not scan %T", src)
+	}
+	var yuan, fen int64
+	if _, err := fmt.Sscanf(s, "%d.%d", &yuan, &fen); err != nil {
+		return err
+	}
+	*m = money(yuan*100 + fen)
+	return nil
+}
+
+func (m money) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", int64(m)/100, int64(m)%100), nil
+}
+
+func TestCodec(t *testing.T) {
+	RegisterCodec("upper", upperCodec{})
+	type TestModel struct {
+		ID    int      `db:"id"`
+		Tags  []string `db:"tags,codec:csv"`
+		Code  string   `db:"code,codec:upper"`
+		Price money    `db:"price"`
+		Cost  *money   `db:"cost"`
+	}
+	cost := money(99)
+	m := &TestModel{ID: 1, Tags: []string{"a", "b"}, Code: "abc", Price: 1050, Cost: &cost}
+	ret, err := ResolveStructValue(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret["tags"] != "a,b" || ret["code"] != "ABC" || ret["price"] != money(1050) || ret["cost"] != &cost {
+		t.Errorf("result: %v", ret)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mrows := sqlmock.NewRows([]string{"id", "tags", "code", "price", "cost"}).AddRow(2, "x,y,z", "XYZ", "3.07", nil)
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(mrows)
+	rows, err := db.Query("select * from test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := &TestModel{}
+	if err := ScanRow(rows, dst); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst.Tags, []string{"x", "y", "z"}) || dst.Code != "xyz" || dst.Price != 307 || dst.Cost != nil {
+		t.Errorf("result: %+v", dst)
+	}
+
+	if err := UpdateModel(dst, map[string]interface{}{"tags": "m,n"}); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(dst.Tags, []string{"m", "n"}) {
+		t.Errorf("result: %v", dst.Tags)
+	}
+}
+
+func TestCodecNotFound(t *testing.T) {
+	type TestModel struct {
+		Tags []string `db:"tags,codec:nope"`
+	}
+	if _, err := ResolveModelStruct(&TestModel{}); err == nil {
+		t.Error("want a codec not found error")
+	}
+}
+
+func TestJSONField(t *testing.T) {
+	type Setting struct {
+		Theme string `json:"theme"`
+	}
+	type TestModel struct {
+		ID      int               `db:"id"`
+		Setting Setting           `db:"setting,json"`
+		Meta    map[string]string `db:"meta,json"`
+		Roles   []int             `db:"roles,json"`
+		Extra   *Setting          `db:"extra,json"`
+	}
+	m := &TestModel{ID: 1, Setting: Setting{"dark"}, Roles: []int{1, 2}}
+	ret, err := ResolveStructValue(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": 1, "setting": `{"theme":"dark"}`, "roles": "[1,2]"}
+	if !reflect.DeepEqual(ret, want) {
+		t.Errorf("result: %v, want: %v", ret, want)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mrows := sqlmock.NewRows([]string{"id", "setting", "meta", "roles", "extra"}).
+		AddRow(2, []byte(`{"theme":"light"}`), `{"a":"b"}`, nil, `{"theme":"x"}`).
+		AddRow(3, nil, nil, "[3]", nil)
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(mrows)
+	rows, err := db.Query("select * from test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst []TestModel
+	if err := ScanAll(rows, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 {
+		t.Fatalf("result: %v", dst)
+	}
+	if dst[0].Setting.Theme != "light" || dst[0].Meta["a"] != "b" || dst[0].Roles != nil || dst[0].Extra == nil || dst[0].Extra.Theme != "x" {
+		t.Errorf("result: %+v", dst[0])
+	}
+	if dst[1].Setting.Theme != "" || dst[1].Meta != nil || !reflect.DeepEqual(dst[1].Roles, []int{3}) || dst[1].Extra != nil {
+		t.Errorf("result: %+v", dst[1])
+	}
+}
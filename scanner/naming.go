@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//NamingStrategy 表名和字段名的命名策略
+//TableName is not called for models which have a TableName method,
+//ColumnName is not called for fields which have a column tag
+type NamingStrategy interface {
+	TableName(name string) string
+	ColumnName(name string) string
+}
+
+//Naming configurable NamingStrategy, the zero value keeps the go type name as table name
+//and FormatName (snake_case) for column names
+//eg: Naming{TablePrefix: "t_", SnakeTable: true, PluralTable: true} UserModel -> t_user_models
+type Naming struct {
+	//TablePrefix eg: "t_"
+	TablePrefix string
+	//SnakeTable UserModel -> user_model
+	SnakeTable bool
+	//PluralTable user -> users
+	PluralTable bool
+	//Table custom table name func, it replaces SnakeTable and PluralTable, the prefix is still added
+	Table func(name string) string
+	//Column custom column name func, default FormatName
+	Column func(name string) string
+}
+
+//TableName impl NamingStrategy
+func (n Naming) TableName(name string) string {
+	if n.Table != nil {
+		return n.TablePrefix + n.Table(name)
+	}
+	if n.SnakeTable {
+		name = SnakeString(name)
+	}
+	if n.PluralTable {
+		name = Pluralize(name)
+	}
+	return n.TablePrefix + name
+}
+
+//ColumnName impl NamingStrategy
+func (n Naming) ColumnName(name string) string {
+	if n.Column != nil {
+		return n.Column(name)
+	}
+	return FormatName(name)
+}
+
+//Pluralize 英文复数, eg: user -> users, category -> categories, box -> boxes
+func Pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+//Mapper resolve models by a NamingStrategy, the resolved models and scan plans are cached per mapper
+type Mapper struct {
+	naming NamingStrategy
+	strict bool
+	cache  *mapperCache
+}
+
+type mapperCache struct {
+	mutex   sync.RWMutex
+	structs map[reflect.Type]*StructData
+	plans   map[scanPlanKey]*scanPlan
+}
+
+//NewMapper ..
+func NewMapper(naming NamingStrategy) *Mapper {
+	if naming == nil {
+		naming = Naming{}
+	}
+	return &Mapper{
+		naming: naming,
+		cache: &mapperCache{
+			structs: make(map[reflect.Type]*StructData),
+			plans:   make(map[scanPlanKey]*scanPlan),
+		},
+	}
+}
+
+//Naming ..
+func (m *Mapper) Naming() NamingStrategy {
+	return m.naming
+}
+
+//DefaultMapper used by the package level functions
+var DefaultMapper = NewMapper(Naming{})
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralize(t *testing.T) {
+	for s, want := range map[string]string{
+		"user":     "users",
+		"category": "categories",
+		"day":      "days",
+		"box":      "boxes",
+		"status":   "statuses",
+		"match":    "matches",
+	} {
+		if ret := Pluralize(s); ret != want {
+			t.Errorf("Pluralize(%s): %s, want: %s", s, ret, want)
+		}
+	}
+}
+
+type UserProfile struct {
+	ID       int64
+	NickName string
+}
+
+func TestNaming(t *testing.T) {
+	for _, c := range []struct {
+		naming NamingStrategy
+		table  string
+		column string
+	}{
+		{Naming{}, "UserProfile", "nick_name"},
+		{Naming{SnakeTable: true}, "user_profile", "nick_name"},
+		{Naming{TablePrefix: "t_", SnakeTable: true, PluralTable: true}, "t_user_profiles", "nick_name"},
+		{Naming{TablePrefix: "t_", Table: strings.ToUpper, Column: strings.ToLower}, "t_USERPROFILE", "nickname"},
+	} {
+		m := NewMapper(c.naming)
+		ret, err := m.ResolveModelStruct(&UserProfile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ret.TableName() != c.table {
+			t.Errorf("table: %s, want: %s", ret.TableName(), c.table)
+		}
+		if ret.GetStructField(c.column) == nil {
+			t.Errorf("column %s not found in %v", c.column, ret.Columns())
+		}
+	}
+	//the table name method is not changed by naming
+	ret, _ := NewMapper(Naming{TablePrefix: "t_"}).ResolveModelStruct(&t1Model{})
+	if ret.TableName() != "tbl_t1" {
+		t.Errorf("table: %s", ret.TableName())
+	}
+}
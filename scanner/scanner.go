@@ -2,18 +2,19 @@ package scanner
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
 	"reflect"
 	"strings"
-	"sync"
+	"time"
 )
 
 /**
 tag 用法
 column 设置行
 	`db:"column:'id'"`
-pk 设为主键 (primary_key)
+pk 设为主键 (primary_key), 多个字段设置pk即为联合主键
 index 普通索引
 unique_index 唯一索引
 auto 自增 (AUTO_INCREMENT)
@@ -21,6 +22,25 @@ size 字段长度
 - 忽略字段
 type 字段类型
 	type:varchar(100)
+embed 展开结构体字段到模型 (匿名结构体默认展开), prefix 设置字段前缀
+	`db:",embed,prefix:billing_"`
+codec 读写时编解码字段, 见 RegisterCodec
+	codec:csv
+json 以json格式读写 struct/map/slice 字段 (同 codec:json)
+omitempty Update时零值不更新 (指针字段非nil即更新)
+readonly 只读字段, 不写入 (Insert/Update/Replace)
+insertonly 只在Insert/Replace时写入, Update不更新
+default 零值时不写入, 使用数据库的默认值, 建表时默认值为类型的零值
+	default:1 建表时的默认值
+generated 生成列 (generated column), 不写入
+optional 严格模式下允许查询结果中没有该字段, 见 Mapper.Strict
+autoCreateTime Insert/Replace时零值自动填充当前时间 (time.Time/*time.Time/sql.NullTime/unix整数), Update不更新
+autoUpdateTime Insert/Replace/Update时自动填充当前时间, 整数字段默认秒, 可设置 milli/nano
+	autoUpdateTime:milli
+version 乐观锁版本字段 (整数), Update时增加 version = ? 条件并加1, 未更新记录时返回 ErrStaleObject
+softdelete 软删除字段, Delete时更新该字段, 查询时默认排除已删除记录
+	时间/指针字段为NULL表示未删除, bool/整数字段为0表示未删除, softdelete:unix (milli/nano) 整数字段记录删除时间
+not null 建表时非空 (非指针字段默认非空), 建表见 schema 包
 其他
 	not null;unique
 **/
@@ -30,6 +50,7 @@ const TagKey = "db"
 const tagSplit = ","
 const tagOptSplit = ":"
 const tagColumn = "COLUMN"
+const tagOptional = "OPTIONAL"
 const tableFuncName = "TableName"
 
 //Debug 模式
@@ -38,11 +59,46 @@ var Debug = false
 //StructField 模型字段
 type StructField struct {
 	column       string
-	index        int
+	index        []int
 	isPrimaryKey bool
 	//模型字段选项
 	options map[string]string
 	plugins []string
+	//codec name, see RegisterCodec
+	codec string
+	typ   reflect.Type
+}
+
+//Column ..
+func (f *StructField) Column() string {
+	return f.column
+}
+
+//Type ..
+func (f *StructField) Type() reflect.Type {
+	return f.typ
+}
+
+//IsPrimaryKey ..
+func (f *StructField) IsPrimaryKey() bool {
+	return f.isPrimaryKey
+}
+
+//IsPtr the field is a pointer, a nil pointer means not set
+func (f *StructField) IsPtr() bool {
+	return f.typ != nil && f.typ.Kind() == reflect.Ptr
+}
+
+//Option the option of tag, eg: Option("size") of `db:"name,size:20"`
+func (f *StructField) Option(name string) (string, bool) {
+	v, ok := f.options[strings.ToUpper(name)]
+	return v, ok
+}
+
+//HasOption the field is tagged with the option, eg: HasOption("omitempty")
+func (f *StructField) HasOption(name string) bool {
+	_, ok := f.Option(name)
+	return ok
 }
 
 //StructData 模型
@@ -50,7 +106,7 @@ type StructData struct {
 	table   string
 	columns []string
 	fields  map[string]*StructField
-	pk      string
+	pks     []string
 	Uniques []string
 	Indexs  []string
 	// ref     *reflect.Value
@@ -69,9 +125,18 @@ func (s StructData) Columns() []string {
 	return s.columns
 }
 
-//GetPk ..
+//GetPk the first column of primary key
+//Deprecated: use PrimaryKeys, a model can have a composite primary key
 func (s StructData) GetPk() string {
-	return s.pk
+	if len(s.pks) == 0 {
+		return ""
+	}
+	return s.pks[0]
+}
+
+//PrimaryKeys columns of the primary key, more than one for a composite primary key
+func (s StructData) PrimaryKeys() []string {
+	return s.pks
 }
 
 //GetStructField ..
@@ -82,12 +147,6 @@ func (s StructData) GetStructField(k string) *StructField {
 	return nil
 }
 
-//反射结构体缓存
-var (
-	refStructCache      = make(map[reflect.Type]*StructData)
-	refStructCacheMutex sync.Mutex
-)
-
 //解析field tags to options
 func parseTagOpts(tags reflect.StructTag) map[string]string {
 	opts := map[string]string{}
@@ -115,7 +174,12 @@ func parseTagOpts(tags reflect.StructTag) map[string]string {
 
 //UpdateModel ..
 func UpdateModel(dst interface{}, list map[string]interface{}) error {
-	modelStruct, err := ResolveModelStruct(dst)
+	return DefaultMapper.UpdateModel(dst, list)
+}
+
+//UpdateModel ..
+func (m *Mapper) UpdateModel(dst interface{}, list map[string]interface{}) error {
+	modelStruct, err := m.ResolveModelStruct(dst)
 	if err != nil {
 		return err
 	}
@@ -123,13 +187,29 @@ func UpdateModel(dst interface{}, list map[string]interface{}) error {
 	// fmt.Printf("%v", structVal.CanSet())
 	for k, v := range list {
 		if field, ok := modelStruct.fields[k]; ok {
-			if structRV.Field(field.index).Kind() == reflect.Ptr {
-				if structRV.Field(field.index).IsNil() && structRV.Field(field.index).CanSet() {
-					structRV.Field(field.index).Set(reflect.New(structRV.Field(field.index).Type().Elem()))
+			fieldRV, ok := fieldByIndex(structRV, field.index, true)
+			if !ok {
+				continue
+			}
+			if field.codec != "" {
+				//the value is encoded by codec
+				if err := decodeField(field, v, fieldRV.Addr().Interface()); err != nil {
+					return err
+				}
+				continue
+			}
+			if v == nil {
+				//NULL
+				fieldRV.Set(reflect.Zero(fieldRV.Type()))
+				continue
+			}
+			if fieldRV.Kind() == reflect.Ptr {
+				if fieldRV.IsNil() && fieldRV.CanSet() {
+					fieldRV.Set(reflect.New(fieldRV.Type().Elem()))
 				}
-				structRV.Field(field.index).Elem().Set(reflect.ValueOf(v))
+				fieldRV.Elem().Set(reflect.ValueOf(v))
 			} else {
-				structRV.Field(field.index).Set(reflect.Indirect(reflect.ValueOf(v)))
+				fieldRV.Set(reflect.Indirect(reflect.ValueOf(v)))
 			}
 		} else {
 			if Debug {
@@ -140,27 +220,63 @@ func UpdateModel(dst interface{}, list map[string]interface{}) error {
 	return nil
 }
 
-func getStructVal(structRV reflect.Value, index int) interface{} {
-	if structRV.Field(index).Kind() == reflect.Ptr {
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+//fieldByIndex get a field by the index path of embedded structs,
+//nil embedded pointers are allocated when alloc is true, otherwise the field is not found
+func fieldByIndex(structRV reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	v := structRV
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func getStructVal(fieldRV reflect.Value) interface{} {
+	//driver.Valuer will be called by database/sql
+	if fieldRV.Type().Implements(valuerType) {
+		if fieldRV.Kind() == reflect.Ptr && fieldRV.IsNil() {
+			return nil
+		}
+		return fieldRV.Interface()
+	}
+	if fieldRV.Kind() == reflect.Ptr {
 		//忽略掉指针为nil 或 未定义情况
-		if structRV.Field(index).IsNil() || structRV.Field(index).Elem().IsValid() {
+		if fieldRV.IsNil() {
 			//指针为nil时候的处理
 			return nil
-			//return reflect.New(structRV.Field(field.index).Type()).Interface()
 		}
-		//零值不忽略 structRV.Field(index).Elem().IsZero()
-		return structRV.Field(index).Elem().Interface()
+		//零值不忽略 fieldRV.Elem().IsZero()
+		return fieldRV.Elem().Interface()
 	}
 	// golang现在无法区分字符串 初始化 和 空值
-	// if structRV.Field(index).IsValid() {
+	// if fieldRV.IsValid() {
 	// 	return nil
 	// }
-	//不要判断零值 structRV.Field(index).IsZero()
-	return structRV.Field(index).Interface()
+	//不要判断零值 fieldRV.IsZero()
+	return fieldRV.Interface()
 }
 
 //ResolveStructValue 解析模型数据到 非零值不解析
 func ResolveStructValue(dst interface{}) (map[string]interface{}, error) {
+	return DefaultMapper.ResolveStructValue(dst)
+}
+
+//ResolveStructValue ..
+func (m *Mapper) ResolveStructValue(dst interface{}) (map[string]interface{}, error) {
 	var list = make(map[string]interface{}, 0)
 	dstRV := reflect.ValueOf(dst)
 	//兼容指针逻辑
@@ -174,12 +290,21 @@ func ResolveStructValue(dst interface{}) (map[string]interface{}, error) {
 	if dstRV.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("scanner: must be a struct, called with non-struct: %v", dstRV.Kind())
 	}
-	modelStruct, err := ResolveModelStruct(dst)
+	modelStruct, err := m.ResolveModelStruct(dst)
 	if err != nil {
 		return list, err
 	}
 	for _, field := range modelStruct.fields {
-		v := getStructVal(dstRV, field.index)
+		fieldRV, ok := fieldByIndex(dstRV, field.index, false)
+		if !ok {
+			continue
+		}
+		v := getStructVal(fieldRV)
+		if field.codec != "" {
+			if v, err = encodeField(field, fieldRV.Interface()); err != nil {
+				return nil, err
+			}
+		}
 		if v != nil {
 			list[field.column] = v
 		}
@@ -187,6 +312,84 @@ func ResolveStructValue(dst interface{}) (map[string]interface{}, error) {
 	return list, nil
 }
 
+//encodeField encode a field value by codec
+func encodeField(field *StructField, v interface{}) (interface{}, error) {
+	codec, ok := GetCodec(field.codec)
+	if !ok {
+		return nil, fmt.Errorf("scanner: codec [%s] not found for column [%s]", field.codec, field.column)
+	}
+	v, err := codec.Encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("scanner: codec [%s] encode column [%s] error: %v", field.codec, field.column, err)
+	}
+	return v, nil
+}
+
+//decodeField decode a column value to field address by codec
+func decodeField(field *StructField, v interface{}, dst interface{}) error {
+	codec, ok := GetCodec(field.codec)
+	if !ok {
+		return fmt.Errorf("scanner: codec [%s] not found for column [%s]", field.codec, field.column)
+	}
+	if err := codec.Decode(v, dst); err != nil {
+		return fmt.Errorf("scanner: codec [%s] decode column [%s] error: %v", field.codec, field.column, err)
+	}
+	return nil
+}
+
+//ResolveStructFilter 解析过滤模型中的非零值字段为查询条件
+//指针字段非nil即视为条件, 可用 op 指定比较符, eg: `db:"age,op:>="`
+func ResolveStructFilter(dst interface{}) (map[string]interface{}, error) {
+	return DefaultMapper.ResolveStructFilter(dst)
+}
+
+//ResolveStructFilter ..
+func (m *Mapper) ResolveStructFilter(dst interface{}) (map[string]interface{}, error) {
+	var list = make(map[string]interface{}, 0)
+	dstRV := reflect.ValueOf(dst)
+	//兼容指针逻辑
+	if dstRV.Kind() == reflect.Ptr {
+		if dstRV.IsNil() {
+			return list, nil
+		}
+		dstRV = dstRV.Elem()
+	}
+	if dstRV.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("scanner: must be a struct, called with non-struct: %v", dstRV.Kind())
+	}
+	modelStruct, err := m.ResolveModelStruct(dst)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range modelStruct.columns {
+		field := modelStruct.fields[column]
+		fieldRV, ok := fieldByIndex(dstRV, field.index, false)
+		if !ok {
+			continue
+		}
+		if fieldRV.Kind() == reflect.Ptr {
+			if fieldRV.IsNil() {
+				continue
+			}
+			fieldRV = fieldRV.Elem()
+		} else if fieldRV.IsZero() {
+			continue
+		}
+		key := column
+		if op, ok := field.options["OP"]; ok && op != "" {
+			key = "[" + op + "]" + column
+		}
+		v := fieldRV.Interface()
+		if field.codec != "" {
+			if v, err = encodeField(field, v); err != nil {
+				return nil, err
+			}
+		}
+		list[key] = v
+	}
+	return list, nil
+}
+
 //SnakeString  转 snake_string
 func SnakeString(s string) string {
 	data := make([]byte, 0, len(s)*2)
@@ -211,13 +414,16 @@ func FormatName(name string) string {
 }
 
 //resolveStruct struct resolve to StructData
-func resolveStruct(structRV reflect.Value) (*StructData, error) {
+func (m *Mapper) resolveStruct(structRV reflect.Value) (*StructData, error) {
 	var structRT reflect.Type
 	structRT = structRV.Type()
-	if modelStruct, ok := refStructCache[structRT]; ok {
+	m.cache.mutex.RLock()
+	modelStruct, ok := m.cache.structs[structRT]
+	m.cache.mutex.RUnlock()
+	if ok {
 		return modelStruct, nil
 	}
-	modelStruct := new(StructData)
+	modelStruct = new(StructData)
 	//这里从value上获取到自定义method上的table name
 	var fnTableName reflect.Value
 	if structRV.CanAddr() {
@@ -228,32 +434,27 @@ func resolveStruct(structRV reflect.Value) (*StructData, error) {
 	if fnTableName.IsValid() {
 		modelStruct.table = fnTableName.Call([]reflect.Value{})[0].Interface().(string)
 	} else {
-		//todo 大小写转换下划线的、自定义方法的
-		// if TableNameFormat == TableNameSnake {
-		// 	name = utils.SnakeString(name)
-		// }
-		modelStruct.table = structRT.Name()
+		modelStruct.table = m.naming.TableName(structRT.Name())
 	}
 	modelStruct.fields = make(map[string]*StructField)
-	for i := 0; i < structRT.NumField(); i++ {
-		f := structRT.Field(i)
-		// skip non-exported fields
-		if f.PkgPath != "" {
-			continue
-		}
-		opts := parseTagOpts(f.Tag)
-		// default to the field name
-		column, ok := opts[tagColumn]
-		if !ok || column == "" {
-			//todo 大小写转换下划线的、自定义方法的
-			column = FormatName(f.Name)
+	fields, err := m.resolveFields(structRT, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	//the shallower field hides the deeper one with the same column, like golang
+	depths := make(map[string]int)
+	for _, field := range fields {
+		if d, ok := depths[field.column]; !ok || len(field.index) < d {
+			depths[field.column] = len(field.index)
 		}
-		// skip using "-" tag fields
-		if column == "-" {
+	}
+	for _, field := range fields {
+		f, column, opts := field.f, field.column, field.options
+		if len(field.index) > depths[column] {
 			continue
 		}
 		if _, ok := modelStruct.fields[column]; ok {
-			return nil, fmt.Errorf("scanner: not found fields on %v for column %s", structRT, column)
+			return nil, fmt.Errorf("scanner: found duplicate fields on %v for column %s", structRT, column)
 		}
 		for k, opt := range opts {
 			switch k {
@@ -264,11 +465,8 @@ func resolveStruct(structRV reflect.Value) (*StructData, error) {
 				if f.Type.Kind() == reflect.Ptr {
 					return nil, fmt.Errorf("scanner: found field %s which is marked as the primary key but is a pointer", f.Name)
 				}
-				//primary key can only be one
-				if modelStruct.pk != "" {
-					return nil, fmt.Errorf("scanner: found field %s which is marked as the primary key, but a primary key field was already found", f.Name)
-				}
-				modelStruct.pk = column
+				//composite primary key in the order of fields
+				modelStruct.pks = append(modelStruct.pks, column)
 			case "UNI", "UNIQUE", "UNIQUE_INDEX":
 				if opt == "" {
 					opt = column
@@ -279,46 +477,142 @@ func resolveStruct(structRV reflect.Value) (*StructData, error) {
 					opt = column
 				}
 				modelStruct.Indexs = append(modelStruct.Indexs, opt)
+			case "CODEC":
+				if _, ok := GetCodec(opt); !ok {
+					return nil, fmt.Errorf("scanner: codec [%s] not found for field %s", opt, f.Name)
+				}
 			default:
 				// if m, ok := marshalers[k]; ok {
 				// field.marshaler =
 				// }
 			}
 		}
-		//未指定情况下寻找id名称的字段
-		if modelStruct.pk == "" && column == "id" {
-			modelStruct.pk = column
-		}
 		modelStruct.columns = append(modelStruct.columns, column)
 		modelStruct.fields[column] = &StructField{
-			column:       column,
-			isPrimaryKey: column == modelStruct.pk,
-			index:        i,
-			options:      opts,
+			column:  column,
+			index:   field.index,
+			options: opts,
+			codec:   fieldCodec(opts),
+			typ:     f.Type,
 			// plugins:    plugins,
 		}
 	}
-	refStructCacheMutex.Lock()
-	defer refStructCacheMutex.Unlock()
-	refStructCache[structRT] = modelStruct
+	//未指定情况下寻找id名称的字段
+	if _, ok := modelStruct.fields["id"]; ok && len(modelStruct.pks) == 0 {
+		modelStruct.pks = []string{"id"}
+	}
+	for _, pk := range modelStruct.pks {
+		modelStruct.fields[pk].isPrimaryKey = true
+	}
+	m.cache.mutex.Lock()
+	defer m.cache.mutex.Unlock()
+	m.cache.structs[structRT] = modelStruct
 	return modelStruct, nil
 }
 
+//structFieldInfo a field found by resolveFields
+type structFieldInfo struct {
+	f       reflect.StructField
+	column  string
+	index   []int
+	options map[string]string
+}
+
+//resolveFields collect fields of a struct in order, embedded structs are flattened
+//eg: `db:",embed,prefix:billing_"`
+func (m *Mapper) resolveFields(structRT reflect.Type, index []int, prefix string) ([]*structFieldInfo, error) {
+	var fields []*structFieldInfo
+	for i := 0; i < structRT.NumField(); i++ {
+		f := structRT.Field(i)
+		opts := parseTagOpts(f.Tag)
+		fieldIndex := append(append([]int{}, index...), i)
+		if isEmbedField(f, opts) {
+			embedRT := f.Type
+			if embedRT.Kind() == reflect.Ptr {
+				embedRT = embedRT.Elem()
+			}
+			embedFields, err := m.resolveFields(embedRT, fieldIndex, prefix+opts["PREFIX"])
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedFields...)
+			continue
+		}
+		// skip non-exported fields
+		if f.PkgPath != "" {
+			continue
+		}
+		// default to the field name
+		column, ok := opts[tagColumn]
+		if !ok || column == "" {
+			column = m.naming.ColumnName(f.Name)
+		}
+		// skip using "-" tag fields
+		if column == "-" {
+			continue
+		}
+		fields = append(fields, &structFieldInfo{
+			f:       f,
+			column:  prefix + column,
+			index:   fieldIndex,
+			options: opts,
+		})
+	}
+	return fields, nil
+}
+
+//isEmbedField anonymous structs (or pointers to them) and fields with embed tag are flattened
+func isEmbedField(f reflect.StructField, opts map[string]string) bool {
+	rt := f.Type
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || opts[tagColumn] == "-" {
+		return false
+	}
+	if _, ok := opts["EMBED"]; ok {
+		return true
+	}
+	if !f.Anonymous || opts[tagColumn] != "" || fieldCodec(opts) != "" {
+		return false
+	}
+	//an unexported embedded pointer can not be allocated
+	if f.PkgPath != "" && f.Type.Kind() == reflect.Ptr {
+		return false
+	}
+	//these are values of a single column
+	if rt == timeType || f.Type.Implements(valuerType) || reflect.PtrTo(f.Type).Implements(scannerType) {
+		return false
+	}
+	return true
+}
+
+//fieldCodec codec name of a field, `db:"meta,json"` means codec json
+func fieldCodec(opts map[string]string) string {
+	if codec := opts["CODEC"]; codec != "" {
+		return codec
+	}
+	if _, ok := opts["JSON"]; ok {
+		return "json"
+	}
+	return ""
+}
+
 //resolveModel model resolve to StructData
-func resolveModel(dstRV reflect.Value) (*StructData, error) {
+func (m *Mapper) resolveModel(dstRV reflect.Value) (*StructData, error) {
 	switch dstRV.Kind() {
 	case reflect.Struct:
-		return resolveStruct(dstRV)
+		return m.resolveStruct(dstRV)
 	case reflect.Ptr:
 		if dstRV.IsZero() {
 			dstRV = reflect.New(dstRV.Type().Elem())
 		}
 		dstRV = dstRV.Elem()
-		return resolveModel(dstRV)
+		return m.resolveModel(dstRV)
 	case reflect.Slice:
 		eltRT := dstRV.Type().Elem()
 		eltRV := reflect.New(eltRT)
-		return resolveModel(eltRV)
+		return m.resolveModel(eltRV)
 	default:
 		return nil, fmt.Errorf("scanner: expects pointer must pointers to struct or slice, found %v", dstRV.Kind())
 	}
@@ -326,93 +620,295 @@ func resolveModel(dstRV reflect.Value) (*StructData, error) {
 
 //ResolveModelStruct 解析模型
 func ResolveModelStruct(dst interface{}) (*StructData, error) {
+	return DefaultMapper.ResolveModelStruct(dst)
+}
+
+//ResolveModelStruct ..
+func (m *Mapper) ResolveModelStruct(dst interface{}) (*StructData, error) {
 	dstRV := reflect.ValueOf(dst)
 	switch dstRV.Kind() {
 	case reflect.Ptr:
 		dstRV = dstRV.Elem()
-		return resolveModel(dstRV)
+		return m.resolveModel(dstRV)
 	case reflect.Slice, reflect.Struct:
-		return resolveModel(dstRV)
+		return m.resolveModel(dstRV)
 	}
 	return nil, fmt.Errorf("scanner: expects pointer must pointers to struct or slice, found %v", dstRV.Kind())
 }
 
+//columnField a result column mapped to a field of the model or of its nested struct
+type columnField struct {
+	field *StructField
+	//field of the model, it is the nested struct field for a nested column
+	root *StructField
+	//index path from the model
+	index []int
+	//index path of the outermost pointer nested struct, it stays nil when all its columns are NULL
+	ptrIndex []int
+}
+
+//nestedSeps separators of nested struct column alias, eg: "user.id" or "user__id"
+var nestedSeps = []string{".", "__"}
+
+//resolveColumn find the field of a result column, nested struct fields are found by column alias
+func (m *Mapper) resolveColumn(dstStruct *StructData, name string) (*columnField, bool) {
+	if field, ok := dstStruct.fields[name]; ok {
+		return &columnField{field: field, root: field, index: field.index}, true
+	}
+	for _, sep := range nestedSeps {
+		i := strings.Index(name, sep)
+		if i <= 0 {
+			continue
+		}
+		field, ok := dstStruct.fields[name[:i]]
+		if !ok || !isNestedField(field) {
+			continue
+		}
+		subRT := field.typ
+		if subRT.Kind() == reflect.Ptr {
+			subRT = subRT.Elem()
+		}
+		subStruct, err := m.resolveStruct(reflect.New(subRT).Elem())
+		if err != nil {
+			continue
+		}
+		sub, ok := m.resolveColumn(subStruct, name[i+len(sep):])
+		if !ok {
+			continue
+		}
+		cf := &columnField{field: sub.field, root: field, index: append(append([]int{}, field.index...), sub.index...)}
+		if field.typ.Kind() == reflect.Ptr {
+			cf.ptrIndex = field.index
+		} else if sub.ptrIndex != nil {
+			cf.ptrIndex = append(append([]int{}, field.index...), sub.ptrIndex...)
+		}
+		return cf, true
+	}
+	return nil, false
+}
+
+//isNestedField a struct (or pointer to struct) field which is not a single column value
+func isNestedField(field *StructField) bool {
+	rt := field.typ
+	if rt == nil || field.codec != "" {
+		return false
+	}
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || rt == timeType {
+		return false
+	}
+	return !field.typ.Implements(valuerType) && !reflect.PtrTo(field.typ).Implements(scannerType)
+}
+
 //Targets ..
 func Targets(dst interface{}, columns []string) ([]interface{}, error) {
-	dstStruct, err := ResolveModelStruct(dst)
+	return DefaultMapper.Targets(dst, columns)
+}
+
+//Targets ..
+func (m *Mapper) Targets(dst interface{}, columns []string) ([]interface{}, error) {
+	plan, err := m.scanPlan(dst, columns)
 	if err != nil {
 		return nil, err
 	}
-	dstRV := reflect.ValueOf(dst)
-	//兼容指针逻辑
-	if dstRV.Kind() == reflect.Ptr {
-		dstRV = dstRV.Elem()
-	}
-	//InterfaceSlice see http://code.google.com/p/go-wiki/wiki/InterfaceSlice
-	var targets = make([]interface{}, len(columns))
-	for i, name := range columns {
-		if field, ok := dstStruct.fields[name]; ok {
-			if dstRV.Field(field.index).CanAddr() {
-				fieldValue := dstRV.Field(field.index).Addr().Interface()
-				switch fieldValue.(type) {
-				// case sql.Scanner:
-				//如果字段有scan方法 则调用
-				// case *time.Time:
-				// 	var scanAddr interface{}
-				// 	scanAddr = new([]uint8)
-				// 	targets = append(targets, scanAddr)
-				default:
-					targets[i] = fieldValue
-				}
-			} else {
-				targets[i] = new(interface{})
-				// targets[i] = new(sql.RawBytes)
-			}
-		} else {
-			//结构体不存在这个字段时候
-			// targets[i] = new(sql.RawBytes)
-			targets[i] = new(interface{})
-			if Debug {
-				log.Printf("scanner: targets column [%s] not found in struct", name)
-			}
-		}
-	}
-	return targets, nil
+	return plan.targets(reflect.Indirect(reflect.ValueOf(dst))), nil
 }
 
 //Plugins ..
 //see https://github.com/russross/meddler/blob/038a8ef02b66198d4db78da3e9830fde52a7e072/meddler.go
 func Plugins(dst interface{}, columns []string, targets []interface{}) error {
-	dstStruct, err := ResolveModelStruct(dst)
+	return DefaultMapper.Plugins(dst, columns, targets)
+}
+
+//Plugins ..
+func (m *Mapper) Plugins(dst interface{}, columns []string, targets []interface{}) error {
+	plan, err := m.scanPlan(dst, columns)
 	if err != nil {
 		return err
 	}
-	dstRV := reflect.ValueOf(dst)
-	//兼容指针逻辑
-	if dstRV.Kind() == reflect.Ptr {
-		dstRV = dstRV.Elem()
+	return plan.plugins(reflect.Indirect(reflect.ValueOf(dst)), targets)
+}
+
+//scanPlan result columns mapped to fields, it is cached per (struct type, columns)
+//so that scanning a row only allocates the targets
+type scanPlan struct {
+	columns []string
+	//nil for a column not found in struct
+	fields []*columnField
+	//some columns need PostRead (codec or pointer nested struct)
+	post bool
+	//for strict mode
+	unmapped []string
+	unfilled []string
+	dstRT    reflect.Type
+}
+
+type scanPlanKey struct {
+	typ     reflect.Type
+	columns string
+}
+
+//scanPlan get the cached plan or build it
+func (m *Mapper) scanPlan(dst interface{}, columns []string) (*scanPlan, error) {
+	dstRT := reflect.TypeOf(dst)
+	for dstRT != nil && dstRT.Kind() == reflect.Ptr {
+		dstRT = dstRT.Elem()
+	}
+	key := scanPlanKey{typ: dstRT, columns: strings.Join(columns, "\x00")}
+	m.cache.mutex.RLock()
+	plan, ok := m.cache.plans[key]
+	m.cache.mutex.RUnlock()
+	if ok {
+		return plan, nil
 	}
-	// for i, name := range data.columns {
+	dstStruct, err := m.ResolveModelStruct(dst)
+	if err != nil {
+		return nil, err
+	}
+	plan = &scanPlan{columns: columns, fields: make([]*columnField, len(columns)), dstRT: dstRT}
+	filled := make(map[*StructField]bool)
 	for i, name := range columns {
-		if field, ok := dstStruct.fields[name]; ok {
-			if dstRV.Field(field.index).CanAddr() {
-				fieldAddr := dstRV.Field(field.index).Addr().Interface()
-				_, _, _ = i, name, fieldAddr
+		cf, ok := m.resolveColumn(dstStruct, name)
+		if !ok {
+			//结构体不存在这个字段时候
+			if Debug {
+				log.Printf("scanner: targets column [%s] not found in struct", name)
 			}
-			if err != nil {
-				return fmt.Errorf("scanner: plugins PostRead error on column [%s]: %v", name, err)
+			plan.unmapped = append(plan.unmapped, name)
+			continue
+		}
+		plan.fields[i] = cf
+		filled[cf.root] = true
+		if cf.field.codec != "" || cf.ptrIndex != nil {
+			plan.post = true
+		}
+	}
+	for _, column := range dstStruct.columns {
+		field := dstStruct.fields[column]
+		if _, ok := field.options[tagOptional]; ok || filled[field] {
+			continue
+		}
+		plan.unfilled = append(plan.unfilled, column)
+	}
+	m.cache.mutex.Lock()
+	defer m.cache.mutex.Unlock()
+	m.cache.plans[key] = plan
+	return plan, nil
+}
+
+//check the plan in strict mode
+func (p *scanPlan) check(strict bool) error {
+	if !strict || (len(p.unmapped) == 0 && len(p.unfilled) == 0) {
+		return nil
+	}
+	return &StrictError{Type: p.dstRT, Columns: p.unmapped, Fields: p.unfilled}
+}
+
+//targets addresses of fields to scan a row
+func (p *scanPlan) targets(dstRV reflect.Value) []interface{} {
+	//InterfaceSlice see http://code.google.com/p/go-wiki/wiki/InterfaceSlice
+	var targets = make([]interface{}, len(p.fields))
+	for i, cf := range p.fields {
+		switch {
+		case cf == nil, cf.field.codec != "":
+			//decode by codec after scan, see Plugins
+			targets[i] = new(interface{})
+		case cf.ptrIndex != nil:
+			//scan to a holder, see Plugins
+			targets[i] = reflect.New(reflect.PtrTo(cf.field.typ)).Interface()
+		default:
+			//如果字段有scan方法 则由database/sql调用
+			fieldRV, _ := fieldByIndex(dstRV, cf.index, true)
+			if fieldRV.IsValid() && fieldRV.CanAddr() {
+				targets[i] = fieldRV.Addr().Interface()
+			} else {
+				targets[i] = new(interface{})
 			}
-		} else {
-			if Debug {
-				log.Printf("scanner: plugins column [%s] not found in struct", name)
+		}
+	}
+	return targets
+}
+
+//plugins PostRead the scanned targets
+func (p *scanPlan) plugins(dstRV reflect.Value, targets []interface{}) error {
+	if !p.post {
+		return nil
+	}
+	//pointer nested structs which have a not NULL column
+	notNull := make(map[string]bool)
+	var nested []int
+	for i, cf := range p.fields {
+		if cf == nil {
+			continue
+		}
+		if cf.ptrIndex != nil {
+			key := fmt.Sprint(cf.ptrIndex)
+			notNull[key] = notNull[key] || !isNullTarget(targets[i])
+			nested = append(nested, i)
+			continue
+		}
+		if err := postRead(dstRV, cf, targets[i]); err != nil {
+			return fmt.Errorf("scanner: plugins PostRead error on column [%s]: %v", p.columns[i], err)
+		}
+	}
+	for _, i := range nested {
+		cf := p.fields[i]
+		if !notNull[fmt.Sprint(cf.ptrIndex)] {
+			//all columns are NULL, keep the pointer nil
+			if ptrRV, ok := fieldByIndex(dstRV, cf.ptrIndex, true); ok && ptrRV.CanSet() {
+				ptrRV.Set(reflect.Zero(ptrRV.Type()))
 			}
+			continue
+		}
+		if err := postRead(dstRV, cf, targets[i]); err != nil {
+			return fmt.Errorf("scanner: plugins PostRead error on column [%s]: %v", p.columns[i], err)
 		}
 	}
 	return nil
 }
 
+//isNullTarget the scanned target is NULL
+func isNullTarget(target interface{}) bool {
+	if v, ok := target.(*interface{}); ok {
+		return *v == nil
+	}
+	return reflect.ValueOf(target).Elem().IsNil()
+}
+
+//postRead set the scanned target to the field, decode by codec or copy from holder
+func postRead(dstRV reflect.Value, cf *columnField, target interface{}) error {
+	if cf.field.codec == "" && cf.ptrIndex == nil {
+		return nil
+	}
+	fieldRV, _ := fieldByIndex(dstRV, cf.index, true)
+	if !fieldRV.IsValid() || !fieldRV.CanAddr() {
+		return nil
+	}
+	if cf.field.codec != "" {
+		v, ok := target.(*interface{})
+		if !ok {
+			return nil
+		}
+		return decodeField(cf.field, *v, fieldRV.Addr().Interface())
+	}
+	holder := reflect.ValueOf(target).Elem()
+	if holder.IsNil() {
+		fieldRV.Set(reflect.Zero(fieldRV.Type()))
+	} else {
+		fieldRV.Set(holder.Elem())
+	}
+	return nil
+}
+
 //Scan ..
 func Scan(rows *sql.Rows, dst interface{}) error {
+	return DefaultMapper.Scan(rows, dst)
+}
+
+//Scan ..
+func (m *Mapper) Scan(rows *sql.Rows, dst interface{}) error {
 	if rows == nil {
 		return fmt.Errorf("scanner: rows is a pointer, but not be a nil")
 	}
@@ -426,33 +922,86 @@ func Scan(rows *sql.Rows, dst interface{}) error {
 		}
 		return sql.ErrNoRows
 	}
-	// bind struct-address to map-address
-	targets, err := Targets(dst, columns)
+	return m.scanColumns(rows, columns, dst)
+}
+
+//ScanCurrent scan the current row, rows.Next must be called before,
+//eg: for rows.Next() { scanner.ScanCurrent(rows, dst) }
+func ScanCurrent(rows *sql.Rows, dst interface{}) error {
+	return DefaultMapper.ScanCurrent(rows, dst)
+}
+
+//ScanCurrent ..
+func (m *Mapper) ScanCurrent(rows *sql.Rows, dst interface{}) error {
+	if rows == nil {
+		return fmt.Errorf("scanner: rows is a pointer, but not be a nil")
+	}
+	columns, err := rows.Columns()
 	if err != nil {
 		return err
 	}
-	if len(columns) != len(targets) {
-		return fmt.Errorf("scanner: mismatch in number of columns (%d) and targets (%d)",
-			len(columns), len(targets))
+	return m.scanColumns(rows, columns, dst)
+}
+
+//scanColumns scan the current row into struct, map or scalar
+func (m *Mapper) scanColumns(rows *sql.Rows, columns []string, dst interface{}) error {
+	switch d := dst.(type) {
+	case map[string]interface{}:
+		return scanMap(rows, columns, d)
+	case *map[string]interface{}:
+		if d == nil {
+			return fmt.Errorf("scanner: Scan called with nil map pointer")
+		}
+		if *d == nil {
+			*d = make(map[string]interface{}, len(columns))
+		}
+		return scanMap(rows, columns, *d)
 	}
-	if err := rows.Scan(targets...); err != nil {
+	if isScalarDst(dst) {
+		return scanScalar(rows, columns, dst)
+	}
+	plan, err := m.scanPlan(dst, columns)
+	if err != nil {
 		return err
 	}
-	// format some field which have tag plugin
-	if err := Plugins(dst, columns, targets); err != nil {
+	if err := plan.check(m.strict); err != nil {
+		return err
+	}
+	if err := plan.scan(rows, reflect.Indirect(reflect.ValueOf(dst))); err != nil {
 		return err
 	}
 	return rows.Err()
 }
 
+//scan the current row into struct
+func (p *scanPlan) scan(rows *sql.Rows, dstRV reflect.Value) error {
+	// bind struct-address to map-address
+	targets := p.targets(dstRV)
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+	// format some field which have tag plugin
+	return p.plugins(dstRV, targets)
+}
+
 //ScanRow ScanRow and Close Rows
 func ScanRow(rows *sql.Rows, dst interface{}) error {
+	return DefaultMapper.ScanRow(rows, dst)
+}
+
+//ScanRow ..
+func (m *Mapper) ScanRow(rows *sql.Rows, dst interface{}) error {
 	defer rows.Close()
-	return Scan(rows, dst)
+	return m.Scan(rows, dst)
 }
 
 //ScanAll ..
 func ScanAll(rows *sql.Rows, dst interface{}) error {
+	return DefaultMapper.ScanAll(rows, dst)
+}
+
+//ScanAll ..
+func (m *Mapper) ScanAll(rows *sql.Rows, dst interface{}) error {
 	defer rows.Close()
 	dstVal := reflect.ValueOf(dst)
 	if dstVal.Kind() != reflect.Ptr || dstVal.IsNil() {
@@ -464,11 +1013,16 @@ func ScanAll(rows *sql.Rows, dst interface{}) error {
 	}
 	sliceRT := sliceRV.Type()
 	eltRT := sliceRT.Elem()
-	if eltRT.Kind() == reflect.Ptr {
+	//single column, eg: *[]int64 or *[]*string
+	scalar := isScalarType(eltRT)
+	if !scalar && eltRT.Kind() == reflect.Ptr {
 		eltRT = eltRT.Elem()
 	}
-	if eltRT.Kind() != reflect.Struct {
-		return fmt.Errorf("scanner: ScanAll expects element to be pointers to structs, found %T", dst)
+	if !scalar && eltRT.Kind() != reflect.Struct && eltRT != mapType {
+		return fmt.Errorf("scanner: ScanAll expects element to be structs, maps or scalars, found %T", dst)
+	}
+	if !scalar && eltRT.Kind() == reflect.Struct {
+		return m.scanAllStruct(rows, sliceRV, eltRT)
 	}
 	// gather the results
 	for {
@@ -476,17 +1030,45 @@ func ScanAll(rows *sql.Rows, dst interface{}) error {
 		eltRV := reflect.New(eltRT)
 		elt := eltRV.Interface()
 		// scan it
-		if err := Scan(rows, elt); err != nil {
+		if err := m.Scan(rows, elt); err != nil {
 			if err == sql.ErrNoRows {
 				return nil
 			}
 			return err
 		}
 		// add struct to slice
-		if sliceRT.Elem().Kind() == reflect.Ptr {
+		if !scalar && sliceRT.Elem().Kind() == reflect.Ptr {
+			sliceRV.Set(reflect.Append(sliceRV, eltRV))
+		} else {
+			sliceRV.Set(reflect.Append(sliceRV, eltRV.Elem()))
+		}
+	}
+}
+
+//scanAllStruct scan all rows into a slice of structs, the scan plan is resolved once
+func (m *Mapper) scanAllStruct(rows *sql.Rows, sliceRV reflect.Value, eltRT reflect.Type) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	plan, err := m.scanPlan(reflect.New(eltRT).Interface(), columns)
+	if err != nil {
+		return err
+	}
+	if err := plan.check(m.strict); err != nil {
+		return err
+	}
+	isPtr := sliceRV.Type().Elem().Kind() == reflect.Ptr
+	for rows.Next() {
+		eltRV := reflect.New(eltRT)
+		if err := plan.scan(rows, eltRV.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
 			sliceRV.Set(reflect.Append(sliceRV, eltRV))
 		} else {
 			sliceRV.Set(reflect.Append(sliceRV, eltRV.Elem()))
 		}
 	}
+	return rows.Err()
 }
@@ -54,8 +54,8 @@ func TestResolveModel5(t *testing.T) {
 	m.ID = 999999
 	m.Name = "jack"
 	ret, _ := ResolveModelStruct(m)
-	if ret.pk != "id" {
-		t.Errorf("result: pk=%v, want: pk=%v", ret.pk, "id")
+	if !reflect.DeepEqual(ret.PrimaryKeys(), []string{"id"}) {
+		t.Errorf("result: pk=%v, want: pk=%v", ret.PrimaryKeys(), "id")
 	}
 	if ret.GetStructField("id").isPrimaryKey != true {
 		t.Errorf("%v", `ret.GetStructField("id").isPrimaryKey`)
@@ -214,3 +214,205 @@ func TestUpdateModel(t *testing.T) {
 	}
 	t.Log(dst)
 }
+
+func TestResolveStructFilter(t *testing.T) {
+	type TestFilter struct {
+		Name   string `db:"name"`
+		Status *int   `db:"status"`
+		MinAge int    `db:"age,op:>="`
+		Nick   string
+	}
+	status := 0
+	f := TestFilter{Name: "jack", Status: &status, MinAge: 18}
+	ret, err := ResolveStructFilter(f)
+	if err != nil {
+		t.Error(err)
+	}
+	want := map[string]interface{}{
+		"name":    "jack",
+		"status":  0,
+		"[>=]age": 18,
+	}
+	if !reflect.DeepEqual(ret, want) {
+		t.Errorf("result: %v, want: %v", ret, want)
+	}
+}
+
+type baseModel struct {
+	ID        int64 `db:"id,pk"`
+	CreatedAt int64 `db:"created_at"`
+}
+
+type Address struct {
+	City string `db:"city"`
+}
+
+func TestResolveEmbedded(t *testing.T) {
+	type TestModel struct {
+		baseModel
+		*Address
+		Name    string  `db:"name"`
+		Billing Address `db:",embed,prefix:billing_"`
+		City    string  `db:"city"`
+	}
+	ret, err := ResolveModelStruct(&TestModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCols := []string{"id", "created_at", "name", "billing_city", "city"}
+	if !reflect.DeepEqual(ret.Columns(), wantCols) {
+		t.Errorf("ret.Columns() result: %v want: %v", ret.Columns(), wantCols)
+	}
+	if ret.GetPk() != "id" {
+		t.Errorf("ret.GetPk() result: %v want: %v", ret.GetPk(), "id")
+	}
+
+	m := &TestModel{Name: "jack", City: "sh"}
+	m.ID = 1
+	m.Billing.City = "bj"
+	val, err := ResolveStructValue(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": int64(1), "created_at": int64(0), "name": "jack", "billing_city": "bj", "city": "sh"}
+	if !reflect.DeepEqual(val, want) {
+		t.Errorf("result: %v, want: %v", val, want)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mrows := sqlmock.NewRows([]string{"id", "created_at", "billing_city"}).AddRow(9, 100, "gz")
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(mrows)
+	rows, err := db.Query("select * from test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := &TestModel{}
+	if err := ScanRow(rows, dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.ID != 9 || dst.CreatedAt != 100 || dst.Billing.City != "gz" {
+		t.Errorf("result: %+v", dst)
+	}
+	if err := UpdateModel(dst, map[string]interface{}{"id": int64(10)}); err != nil {
+		t.Error(err)
+	}
+	if dst.ID != 10 {
+		t.Errorf("result: %v", dst.ID)
+	}
+}
+
+func TestResolveEmbeddedPtr(t *testing.T) {
+	type Extra struct {
+		Note string `db:"note"`
+	}
+	type TestModel struct {
+		ID int `db:"id"`
+		*Extra
+	}
+	val, err := ResolveStructValue(&TestModel{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := val["note"]; ok {
+		t.Errorf("result: %v, nil embedded pointer should be skipped", val)
+	}
+	dst := &TestModel{}
+	if err := UpdateModel(dst, map[string]interface{}{"note": "hi"}); err != nil {
+		t.Error(err)
+	}
+	if dst.Extra == nil || dst.Note != "hi" {
+		t.Errorf("result: %+v", dst)
+	}
+}
+
+func TestScanNested(t *testing.T) {
+	type User struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	type Order struct {
+		ID    int      `db:"id"`
+		Tags  []string `db:"tags,codec:csv"`
+		Price *int     `db:"price"`
+	}
+	type UserOrder struct {
+		User  User   `db:"user"`
+		Order *Order `db:"order"`
+		Total int    `db:"total"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mrows := sqlmock.NewRows([]string{"user.id", "user__name", "order.id", "order.tags", "order__price", "total"}).
+		AddRow(1, "tom", 10, "a,b", nil, 3).
+		AddRow(2, "jack", nil, nil, nil, 0)
+	mock.ExpectQuery("select (.+) from users").WillReturnRows(mrows)
+	rows, err := db.Query("select * from users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst []UserOrder
+	if err := ScanAll(rows, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 {
+		t.Fatalf("result: %v", dst)
+	}
+	if dst[0].User.ID != 1 || dst[0].User.Name != "tom" || dst[0].Total != 3 {
+		t.Errorf("result: %+v", dst[0])
+	}
+	if dst[0].Order == nil || dst[0].Order.ID != 10 || !reflect.DeepEqual(dst[0].Order.Tags, []string{"a", "b"}) || dst[0].Order.Price != nil {
+		t.Errorf("result: %+v", dst[0].Order)
+	}
+	if dst[1].User.ID != 2 || dst[1].Order != nil {
+		t.Errorf("result: %+v", dst[1])
+	}
+}
+
+func TestResolveStructValuePtr(t *testing.T) {
+	type TestModel struct {
+		ID   int     `db:"id"`
+		Name *string `db:"name"`
+		Age  *int    `db:"age"`
+	}
+	name := ""
+	ret, err := ResolveStructValue(&TestModel{ID: 1, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": 1, "name": ""}
+	if !reflect.DeepEqual(ret, want) {
+		t.Errorf("result: %v, want: %v", ret, want)
+	}
+	dst := &TestModel{Name: &name}
+	if err := UpdateModel(dst, map[string]interface{}{"name": nil, "age": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != nil || *dst.Age != 3 {
+		t.Errorf("result: %v", dst)
+	}
+}
+
+func TestResolveCompositeKey(t *testing.T) {
+	type TestModel struct {
+		ID     int64 `db:"id"`
+		UserID int64 `db:"user_id,pk"`
+		RoleID int64 `db:"role_id,pk"`
+	}
+	ret, err := ResolveModelStruct(&TestModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ret.PrimaryKeys(), []string{"user_id", "role_id"}) {
+		t.Errorf("result: %v", ret.PrimaryKeys())
+	}
+	if ret.GetStructField("id").IsPrimaryKey() || !ret.GetStructField("role_id").IsPrimaryKey() {
+		t.Error("id is not a primary key when pk is tagged")
+	}
+}
@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//Strict a mapper sharing the cache in strict mode or not,
+//in strict mode scanning into struct returns a *StrictError when a result column is not found in struct
+//or a field (not tagged optional) is not in the result
+func (m *Mapper) Strict(strict bool) *Mapper {
+	return &Mapper{naming: m.naming, strict: strict, cache: m.cache}
+}
+
+//IsStrict ..
+func (m *Mapper) IsStrict() bool {
+	return m.strict
+}
+
+//StrictError the result columns do not match the struct in strict mode
+type StrictError struct {
+	Type reflect.Type
+	//Columns result columns not found in struct
+	Columns []string
+	//Fields columns of struct not in result
+	Fields []string
+}
+
+func (e *StrictError) Error() string {
+	var msgs []string
+	if len(e.Columns) > 0 {
+		msgs = append(msgs, fmt.Sprintf("columns [%s] not found in struct", strings.Join(e.Columns, ", ")))
+	}
+	if len(e.Fields) > 0 {
+		msgs = append(msgs, fmt.Sprintf("fields [%s] not filled by result", strings.Join(e.Fields, ", ")))
+	}
+	return fmt.Sprintf("scanner: strict mode %v: %s", e.Type, strings.Join(msgs, ", "))
+}
@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestStrict(t *testing.T) {
+	type strictModel struct {
+		ID    int64  `db:"id"`
+		Name  string `db:"name"`
+		Email string `db:"email"`
+		Note  string `db:"note,optional"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).AddRow(1, "tom", 3))
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).AddRow(1, "tom", 3))
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "tom", "a@b.c"))
+
+	m := NewMapper(nil)
+	rows, _ := db.Query("select * from test")
+	var list []strictModel
+	if err := m.ScanAll(rows, &list); err != nil || len(list) != 1 {
+		t.Errorf("result: %v, %v", list, err)
+	}
+
+	rows, _ = db.Query("select * from test")
+	err = m.Strict(true).ScanRow(rows, &strictModel{})
+	var strictErr *StrictError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("want StrictError, found %v", err)
+	}
+	if !reflect.DeepEqual(strictErr.Columns, []string{"age"}) || !reflect.DeepEqual(strictErr.Fields, []string{"email"}) {
+		t.Errorf("result: %v", strictErr)
+	}
+	want := "scanner: strict mode scanner.strictModel: columns [age] not found in struct, fields [email] not filled by result"
+	if err.Error() != want {
+		t.Errorf("result: %s, want: %s", err, want)
+	}
+
+	rows, _ = db.Query("select * from test")
+	list = nil
+	if err := m.Strict(true).ScanAll(rows, &list); err != nil || len(list) != 1 {
+		t.Errorf("result: %v, %v", list, err)
+	}
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var mapType = reflect.TypeOf(map[string]interface{}{})
+
+//binaryTypes column types which keep []byte when scan into map
+var binaryTypes = []string{"BLOB", "BINARY", "BIT", "BYTEA", "GEOMETRY"}
+
+//scanMap scan a row into map, []byte is converted to string by column type
+func scanMap(rows *sql.Rows, columns []string, dst map[string]interface{}) error {
+	if dst == nil {
+		return fmt.Errorf("scanner: Scan called with nil map")
+	}
+	values := make([]interface{}, len(columns))
+	targets := make([]interface{}, len(columns))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+	types, _ := rows.ColumnTypes()
+	for i, name := range columns {
+		if b, ok := values[i].([]byte); ok {
+			if i < len(types) && isBinaryType(types[i].DatabaseTypeName()) {
+				values[i] = append([]byte{}, b...)
+			} else {
+				values[i] = string(b)
+			}
+		}
+		dst[name] = values[i]
+	}
+	return rows.Err()
+}
+
+func isBinaryType(typ string) bool {
+	typ = strings.ToUpper(typ)
+	for _, t := range binaryTypes {
+		if strings.Contains(typ, t) {
+			return true
+		}
+	}
+	return false
+}
+
+//scanScalar scan a single column row into a scalar pointer
+func scanScalar(rows *sql.Rows, columns []string, dst interface{}) error {
+	if len(columns) != 1 {
+		return fmt.Errorf("scanner: scan into %T expects a single column, found %d columns", dst, len(columns))
+	}
+	if err := rows.Scan(dst); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+//isScalarDst dst is a pointer to a scalar, eg: *int64, *string, *time.Time, **string
+func isScalarDst(dst interface{}) bool {
+	rt := reflect.TypeOf(dst)
+	if rt == nil || rt.Kind() != reflect.Ptr {
+		return false
+	}
+	return isScalarType(rt.Elem())
+}
+
+//isScalarType a single column value, not a model struct or a map
+func isScalarType(rt reflect.Type) bool {
+	if rt.Implements(scannerType) || reflect.PtrTo(rt).Implements(scannerType) {
+		return true
+	}
+	if rt.Kind() == reflect.Ptr {
+		return isScalarType(rt.Elem())
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		return rt == timeType
+	case reflect.Map, reflect.Chan, reflect.Func, reflect.Interface, reflect.Invalid, reflect.UnsafePointer:
+		return false
+	case reflect.Slice:
+		return rt.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
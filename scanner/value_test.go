@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestScanMap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mrows := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("BIGINT", int64(0)),
+		sqlmock.NewColumn("name").OfType("VARCHAR", ""),
+		sqlmock.NewColumn("avatar").OfType("BLOB", []byte{}),
+	).AddRow(int64(1), []byte("tom"), []byte{1, 2}).AddRow(int64(2), nil, nil)
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(mrows)
+	rows, err := db.Query("select * from test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst []map[string]interface{}
+	if err := ScanAll(rows, &dst); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{
+		{"id": int64(1), "name": "tom", "avatar": []byte{1, 2}},
+		{"id": int64(2), "name": nil, "avatar": nil},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("result: %v, want: %v", dst, want)
+	}
+
+	mock.ExpectQuery("select (.+) from test").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	rows, err = db.Query("select * from test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := map[string]interface{}{}
+	if err := ScanRow(rows, row); err != nil {
+		t.Fatal(err)
+	}
+	if row["id"] != int64(3) {
+		t.Errorf("result: %v", row)
+	}
+}
+
+func TestScanScalars(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("select id from test").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery("select name from test").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow(nil))
+	mock.ExpectQuery("select name from test").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow("b"))
+	mock.ExpectQuery("select id, name from test").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+
+	rows, _ := db.Query("select id from test")
+	var ids []int64
+	if err := ScanAll(rows, &ids); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Errorf("result: %v", ids)
+	}
+
+	rows, _ = db.Query("select name from test")
+	var names []*string
+	if err := ScanAll(rows, &names); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || *names[0] != "a" || names[1] != nil {
+		t.Errorf("result: %v", names)
+	}
+
+	rows, _ = db.Query("select name from test")
+	var nulls []sql.NullString
+	if err := ScanAll(rows, &nulls); err != nil {
+		t.Fatal(err)
+	}
+	if len(nulls) != 2 || nulls[1].String != "b" {
+		t.Errorf("result: %v", nulls)
+	}
+
+	rows, _ = db.Query("select id, name from test")
+	var bad []string
+	if err := ScanAll(rows, &bad); err == nil {
+		t.Error("want a single column error")
+	}
+}
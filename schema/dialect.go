@@ -0,0 +1,284 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Dialect the sql dialect of DDL
+type Dialect interface {
+	//Name eg: mysql
+	Name() string
+	//Quote quote an identifier
+	Quote(name string) string
+	//DataType the column type of a go type (not a pointer), size is the size tag (0 if not set),
+	//"" if the type is not supported
+	DataType(rt reflect.Type, size int) string
+	//JSONType the column type of a json field
+	JSONType() string
+	//AutoIncrement the column type and the keyword of an auto increment column,
+	//primaryKey the column is the single primary key,
+	//eg: BIGINT => BIGINT, AUTO_INCREMENT for mysql and BIGSERIAL, "" for postgres
+	AutoIncrement(typ string, primaryKey bool) (string, string)
+	//InlineIndex the indexes are defined in CREATE TABLE, otherwise by CREATE INDEX
+	InlineIndex() bool
+	//Placeholder the positional placeholder of the i-th (from 1) arg, eg: ? for mysql and $1 for postgres
+	Placeholder(i int) string
+}
+
+var (
+	dialects      = make(map[string]Dialect)
+	dialectsMutex sync.RWMutex
+)
+
+//RegisterDialect register a dialect by the name of driver
+func RegisterDialect(driver string, d Dialect) {
+	dialectsMutex.Lock()
+	defer dialectsMutex.Unlock()
+	dialects[strings.ToLower(driver)] = d
+}
+
+//GetDialect get a dialect by the name of driver
+func GetDialect(driver string) (Dialect, bool) {
+	dialectsMutex.RLock()
+	defer dialectsMutex.RUnlock()
+	d, ok := dialects[strings.ToLower(driver)]
+	return d, ok
+}
+
+func init() {
+	RegisterDialect("mysql", MySQL{})
+	RegisterDialect("postgres", Postgres{})
+	RegisterDialect("pgx", Postgres{})
+	RegisterDialect("sqlite3", SQLite{})
+	RegisterDialect("sqlite", SQLite{})
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+	nullTypes = map[reflect.Type]reflect.Type{
+		reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+		reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+		reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+		reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+		reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+		reflect.TypeOf(sql.NullTime{}):    timeType,
+	}
+)
+
+//quote quote an identifier by q and escape the embedded quotes
+func quote(name string, q string) string {
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+//MySQL dialect
+type MySQL struct{}
+
+//Name ..
+func (MySQL) Name() string {
+	return "mysql"
+}
+
+//Quote ..
+func (MySQL) Quote(name string) string {
+	return quote(name, "`")
+}
+
+//DataType ..
+func (MySQL) DataType(rt reflect.Type, size int) string {
+	switch rt {
+	case timeType:
+		return "DATETIME"
+	case bytesType:
+		return "BLOB"
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8:
+		return "TINYINT"
+	case reflect.Int16:
+		return "SMALLINT"
+	case reflect.Int32:
+		return "INT"
+	case reflect.Int, reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint8:
+		return "TINYINT UNSIGNED"
+	case reflect.Uint16:
+		return "SMALLINT UNSIGNED"
+	case reflect.Uint32:
+		return "INT UNSIGNED"
+	case reflect.Uint, reflect.Uint64:
+		return "BIGINT UNSIGNED"
+	case reflect.Float32:
+		return "FLOAT"
+	case reflect.Float64:
+		return "DOUBLE"
+	case reflect.String:
+		if size <= 0 {
+			size = 255
+		}
+		if size > 65535 {
+			return "LONGTEXT"
+		}
+		return fmt.Sprintf("VARCHAR(%d)", size)
+	}
+	return ""
+}
+
+//JSONType ..
+func (MySQL) JSONType() string {
+	return "JSON"
+}
+
+//AutoIncrement ..
+func (MySQL) AutoIncrement(typ string, primaryKey bool) (string, string) {
+	return typ, "AUTO_INCREMENT"
+}
+
+//InlineIndex ..
+func (MySQL) InlineIndex() bool {
+	return true
+}
+
+//Placeholder ..
+func (MySQL) Placeholder(i int) string {
+	return "?"
+}
+
+//Postgres dialect
+type Postgres struct{}
+
+//Name ..
+func (Postgres) Name() string {
+	return "postgres"
+}
+
+//Quote ..
+func (Postgres) Quote(name string) string {
+	return quote(name, `"`)
+}
+
+//DataType ..
+func (Postgres) DataType(rt reflect.Type, size int) string {
+	switch rt {
+	case timeType:
+		return "TIMESTAMPTZ"
+	case bytesType:
+		return "BYTEA"
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "SMALLINT"
+	case reflect.Int32, reflect.Uint16:
+		return "INTEGER"
+	case reflect.Int, reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		return "DOUBLE PRECISION"
+	case reflect.String:
+		if size > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", size)
+		}
+		return "TEXT"
+	}
+	return ""
+}
+
+//JSONType ..
+func (Postgres) JSONType() string {
+	return "JSONB"
+}
+
+//AutoIncrement ..
+func (Postgres) AutoIncrement(typ string, primaryKey bool) (string, string) {
+	switch typ {
+	case "SMALLINT":
+		return "SMALLSERIAL", ""
+	case "INTEGER":
+		return "SERIAL", ""
+	}
+	return "BIGSERIAL", ""
+}
+
+//InlineIndex ..
+func (Postgres) InlineIndex() bool {
+	return false
+}
+
+//Placeholder ..
+func (Postgres) Placeholder(i int) string {
+	return "$" + strconv.Itoa(i)
+}
+
+//SQLite dialect
+type SQLite struct{}
+
+//Name ..
+func (SQLite) Name() string {
+	return "sqlite3"
+}
+
+//Quote ..
+func (SQLite) Quote(name string) string {
+	return quote(name, `"`)
+}
+
+//DataType ..
+func (SQLite) DataType(rt reflect.Type, size int) string {
+	switch rt {
+	case timeType:
+		return "DATETIME"
+	case bytesType:
+		return "BLOB"
+	}
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	case reflect.String:
+		if size > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", size)
+		}
+		return "TEXT"
+	}
+	return ""
+}
+
+//JSONType ..
+func (SQLite) JSONType() string {
+	return "TEXT"
+}
+
+//AutoIncrement only the INTEGER PRIMARY KEY can be auto increment, the keyword is dropped for other columns
+func (SQLite) AutoIncrement(typ string, primaryKey bool) (string, string) {
+	if !primaryKey {
+		return typ, ""
+	}
+	return "INTEGER", "AUTOINCREMENT"
+}
+
+//InlineIndex ..
+func (SQLite) InlineIndex() bool {
+	return false
+}
+
+//Placeholder ..
+func (SQLite) Placeholder(i int) string {
+	return "?"
+}
@@ -0,0 +1,234 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//addIndexColumn add a column to the index (or the primary key) of table in the order of rows
+func addIndexColumn(t *Table, name, column string, unique, primary bool) {
+	if primary {
+		t.PrimaryKeys = append(t.PrimaryKeys, column)
+		return
+	}
+	for _, idx := range t.Indexes {
+		if idx.Name == name {
+			idx.Columns = append(idx.Columns, column)
+			return
+		}
+	}
+	t.Indexes = append(t.Indexes, &Index{Name: name, Columns: []string{column}, Unique: unique})
+}
+
+//Inspect read the table from information_schema
+func (MySQL) Inspect(ctx context.Context, q Queryer, table string) (*Table, error) {
+	rows, err := q.QueryContext(ctx, "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA FROM information_schema.COLUMNS "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	t := &Table{Name: table}
+	for rows.Next() {
+		var name, typ, nullable, extra string
+		var def sql.NullString
+		if err := rows.Scan(&name, &typ, &nullable, &def, &extra); err != nil {
+			return nil, err
+		}
+		t.Columns = append(t.Columns, &Column{
+			Name:          name,
+			Type:          strings.ToUpper(typ),
+			Nullable:      nullable == "YES",
+			Default:       def.String,
+			AutoIncrement: strings.Contains(strings.ToLower(extra), "auto_increment"),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, nil
+	}
+	rows, err = q.QueryContext(ctx, "SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE FROM information_schema.STATISTICS "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, column string
+		var nonUnique int
+		if err := rows.Scan(&name, &column, &nonUnique); err != nil {
+			return nil, err
+		}
+		addIndexColumn(t, name, column, nonUnique == 0, name == "PRIMARY")
+	}
+	return t, rows.Err()
+}
+
+//mysqlIntWidth the display width of integer types, eg: bigint(20)
+var mysqlIntWidth = regexp.MustCompile(`^(TINYINT|SMALLINT|MEDIUMINT|INT|BIGINT)\(\d+\)`)
+
+//SameType the display width of integer is ignored and BOOLEAN is TINYINT(1)
+func (MySQL) SameType(model, current string) bool {
+	normalize := func(typ string) string {
+		typ = strings.ToUpper(strings.TrimSpace(typ))
+		switch typ {
+		case "BOOLEAN", "BOOL":
+			return "TINYINT"
+		case "INTEGER":
+			return "INT"
+		}
+		return mysqlIntWidth.ReplaceAllString(typ, "$1")
+	}
+	return normalize(model) == normalize(current)
+}
+
+//AlterColumn ..
+func (d MySQL) AlterColumn(table string, col *Column) string {
+	return "ALTER TABLE " + d.Quote(table) + " MODIFY COLUMN " + ColumnDefinition(d, col, false)
+}
+
+//AddColumn the existing rows get the implicit default of type (eg: 0 or an empty string) on mysql
+func (d MySQL) AddColumn(table string, col *Column) string {
+	return "ALTER TABLE " + d.Quote(table) + " ADD COLUMN " + ColumnDefinition(d, col, false)
+}
+
+//Inspect read the table from information_schema and pg_index of the current schema
+func (Postgres) Inspect(ctx context.Context, q Queryer, table string) (*Table, error) {
+	rows, err := q.QueryContext(ctx, "SELECT column_name, data_type, character_maximum_length, is_nullable, column_default FROM information_schema.columns "+
+		"WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	t := &Table{Name: table}
+	for rows.Next() {
+		var name, typ, nullable string
+		var size sql.NullInt64
+		var def sql.NullString
+		if err := rows.Scan(&name, &typ, &size, &nullable, &def); err != nil {
+			return nil, err
+		}
+		typ = strings.ToUpper(typ)
+		switch typ {
+		case "CHARACTER VARYING":
+			typ = "VARCHAR"
+			if size.Valid {
+				typ = fmt.Sprintf("VARCHAR(%d)", size.Int64)
+			}
+		case "TIMESTAMP WITH TIME ZONE":
+			typ = "TIMESTAMPTZ"
+		}
+		col := &Column{Name: name, Type: typ, Nullable: nullable == "YES", Default: def.String}
+		if strings.HasPrefix(def.String, "nextval(") {
+			col.AutoIncrement, col.Default = true, ""
+		}
+		t.Columns = append(t.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, nil
+	}
+	rows, err = q.QueryContext(ctx, "SELECT i.relname, a.attname, ix.indisunique, ix.indisprimary FROM pg_index ix "+
+		"JOIN pg_class t ON t.oid = ix.indrelid JOIN pg_class i ON i.oid = ix.indexrelid "+
+		"JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey) "+
+		"WHERE t.relname = $1 AND t.relnamespace = current_schema()::regnamespace "+
+		"ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, column string
+		var unique, primary bool
+		if err := rows.Scan(&name, &column, &unique, &primary); err != nil {
+			return nil, err
+		}
+		addIndexColumn(t, name, column, unique, primary)
+	}
+	return t, rows.Err()
+}
+
+//SameType ..
+func (Postgres) SameType(model, current string) bool {
+	return strings.EqualFold(strings.TrimSpace(model), strings.TrimSpace(current))
+}
+
+//AlterColumn ..
+func (d Postgres) AlterColumn(table string, col *Column) string {
+	return "ALTER TABLE " + d.Quote(table) + " ALTER COLUMN " + d.Quote(col.Name) + " TYPE " + col.Type
+}
+
+//AddColumn a NOT NULL column gets the zero value of its type as the default
+func (d Postgres) AddColumn(table string, col *Column) string {
+	return addColumnWithDefault(d, table, col)
+}
+
+//Inspect read the table by pragma_table_info and pragma_index_list
+func (SQLite) Inspect(ctx context.Context, q Queryer, table string) (*Table, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	t := &Table{Name: table}
+	//pk is the position of column in the primary key
+	pks := make(map[int]string)
+	for rows.Next() {
+		var name, typ string
+		var notNull, pk int
+		var def sql.NullString
+		if err := rows.Scan(&name, &typ, &notNull, &def, &pk); err != nil {
+			return nil, err
+		}
+		t.Columns = append(t.Columns, &Column{Name: name, Type: strings.ToUpper(typ), Nullable: notNull == 0, Default: def.String})
+		if pk > 0 {
+			pks[pk] = name
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, nil
+	}
+	for i := 1; i <= len(pks); i++ {
+		t.PrimaryKeys = append(t.PrimaryKeys, pks[i])
+	}
+	rows, err = q.QueryContext(ctx, `SELECT il.name, ii.name, il."unique" FROM pragma_index_list(?) il, pragma_index_info(il.name) ii `+
+		`WHERE il.origin = 'c' ORDER BY il.name, ii.seqno`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, column string
+		var unique int
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return nil, err
+		}
+		addIndexColumn(t, name, column, unique == 1, false)
+	}
+	return t, rows.Err()
+}
+
+//SameType ..
+func (SQLite) SameType(model, current string) bool {
+	return strings.EqualFold(strings.TrimSpace(model), strings.TrimSpace(current))
+}
+
+//AlterColumn sqlite can not change the type of a column
+func (SQLite) AlterColumn(table string, col *Column) string {
+	return ""
+}
+
+//AddColumn a NOT NULL column gets the zero value of its type as the default, as sqlite requires a default
+func (d SQLite) AddColumn(table string, col *Column) string {
+	return addColumnWithDefault(d, table, col)
+}
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+//Queryer a *sql.DB, *sql.Tx or *gosql.Session to read the tables
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+//Migrator a dialect which reads the tables of db and alters them, the built-in dialects are migrators
+type Migrator interface {
+	Dialect
+	//Inspect read the table from the catalog of db, nil if the table does not exist,
+	//the column types are in the form of DataType
+	Inspect(ctx context.Context, q Queryer, table string) (*Table, error)
+	//SameType the column type of model is the type of db
+	SameType(model, current string) bool
+	//AlterColumn the statement to change the column type, "" if not supported
+	AlterColumn(table string, col *Column) string
+	//AddColumn the statement to add a column to a table which may have rows, "" if not supported
+	AddColumn(table string, col *Column) string
+}
+
+//Change a change of a table to migrate
+type Change struct {
+	Table string
+	//Desc eg: add column `name` VARCHAR(64)
+	Desc string
+	//SQL the statement, "" if the change is not supported by the dialect
+	SQL string
+	//Destructive the change may lose data or break the running code (eg: a type change),
+	//it is not applied by AutoMigrate
+	Destructive bool
+}
+
+//Diff the changes from the current table (nil if it does not exist) to the table of model:
+//new tables, added columns, new indexes and type changes,
+//the columns and indexes which are not in the model are kept
+func Diff(m Migrator, model, current *Table) []Change {
+	var changes []Change
+	if current == nil {
+		for _, stmt := range CreateTable(m, model, false) {
+			changes = append(changes, Change{Table: model.Name, Desc: "create table " + model.Name, SQL: stmt})
+		}
+		return changes
+	}
+	for _, col := range model.Columns {
+		exist := current.Column(col.Name)
+		if exist == nil {
+			change := Change{
+				Table: model.Name,
+				Desc:  fmt.Sprintf("add column %s %s", col.Name, col.Type),
+				SQL:   m.AddColumn(model.Name, col),
+			}
+			if change.SQL == "" {
+				change.Desc += " NOT NULL without a default"
+			}
+			changes = append(changes, change)
+			continue
+		}
+		if !m.SameType(col.Type, exist.Type) {
+			changes = append(changes, Change{
+				Table:       model.Name,
+				Desc:        fmt.Sprintf("change type of column %s from %s to %s", col.Name, exist.Type, col.Type),
+				SQL:         m.AlterColumn(model.Name, col),
+				Destructive: true,
+			})
+		}
+	}
+	indexes := make(map[string]bool, len(current.Indexes))
+	for _, idx := range current.Indexes {
+		indexes[idx.Name] = true
+	}
+	for _, idx := range model.Indexes {
+		if indexes[idx.Name] {
+			continue
+		}
+		changes = append(changes, Change{
+			Table: model.Name,
+			Desc:  "add index " + idx.Name,
+			SQL:   CreateIndex(m, model.Name, idx, false),
+		})
+	}
+	return changes
+}
+
+//addColumnWithDefault add a NOT NULL column by the zero value of its type as the default,
+//the existing rows need a value, "" if the type has no zero value
+func addColumnWithDefault(d Dialect, table string, col *Column) string {
+	if !col.Nullable && col.Default == "" && !col.AutoIncrement {
+		zero := zeroDefault(col.Type)
+		if zero == "" {
+			return ""
+		}
+		c := *col
+		c.Default = zero
+		col = &c
+	}
+	return "ALTER TABLE " + d.Quote(table) + " ADD COLUMN " + ColumnDefinition(d, col, false)
+}
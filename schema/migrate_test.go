@@ -0,0 +1,204 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDiff(t *testing.T) {
+	model, err := Parse(MySQL{}, nil, &user{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := Diff(MySQL{}, model, nil); len(changes) != 1 || changes[0].Destructive {
+		t.Errorf("result: %+v", changes)
+	}
+	current := &Table{
+		Name: "user",
+		Columns: []*Column{
+			{Name: "id", Type: "BIGINT(20)", AutoIncrement: true},
+			{Name: "name", Type: "VARCHAR(32)"},
+			{Name: "nickname", Type: "VARCHAR(255)", Nullable: true},
+			{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			{Name: "status", Type: "TINYINT(4)"},
+			{Name: "score", Type: "DOUBLE"},
+			{Name: "meta", Type: "JSON"},
+			{Name: "legacy", Type: "INT"},
+		},
+		PrimaryKeys: []string{"id"},
+		Indexes:     []*Index{{Name: "uk_user_name", Columns: []string{"name"}, Unique: true}},
+	}
+	want := []Change{
+		{Table: "user", Desc: "change type of column name from VARCHAR(32) to VARCHAR(64)",
+			SQL: "ALTER TABLE `user` MODIFY COLUMN `name` VARCHAR(64) NOT NULL", Destructive: true},
+		{Table: "user", Desc: "add column amount TEXT", SQL: "ALTER TABLE `user` ADD COLUMN `amount` TEXT NOT NULL"},
+		{Table: "user", Desc: "add column created_at DATETIME", SQL: "ALTER TABLE `user` ADD COLUMN `created_at` DATETIME NOT NULL"},
+		{Table: "user", Desc: "add index idx_email_status", SQL: "CREATE INDEX `idx_email_status` ON `user` (`email`, `status`)"},
+	}
+	if changes := Diff(MySQL{}, model, current); !reflect.DeepEqual(changes, want) {
+		t.Errorf("result:\n%+v\nwant\n%+v", changes, want)
+	}
+	if changes := Diff(SQLite{}, &Table{Name: "t", Columns: []*Column{{Name: "a", Type: "TEXT"}}},
+		&Table{Name: "t", Columns: []*Column{{Name: "a", Type: "integer"}}}); len(changes) != 1 || changes[0].SQL != "" {
+		t.Errorf("result: %+v", changes)
+	}
+}
+
+func TestDiffAddColumn(t *testing.T) {
+	for _, c := range []struct {
+		m    Migrator
+		want []string
+	}{
+		{MySQL{}, []string{
+			"ALTER TABLE `user` ADD COLUMN `name` VARCHAR(64) NOT NULL",
+			"ALTER TABLE `user` ADD COLUMN `nickname` VARCHAR(255) NULL",
+			"ALTER TABLE `user` ADD COLUMN `status` TINYINT NOT NULL DEFAULT 1",
+			"ALTER TABLE `user` ADD COLUMN `score` DOUBLE NOT NULL",
+			"ALTER TABLE `user` ADD COLUMN `created_at` DATETIME NOT NULL",
+		}},
+		{Postgres{}, []string{
+			`ALTER TABLE "user" ADD COLUMN "name" VARCHAR(64) NOT NULL DEFAULT ''`,
+			`ALTER TABLE "user" ADD COLUMN "nickname" TEXT NULL`,
+			`ALTER TABLE "user" ADD COLUMN "status" SMALLINT NOT NULL DEFAULT 1`,
+			`ALTER TABLE "user" ADD COLUMN "score" DOUBLE PRECISION NOT NULL DEFAULT 0`,
+			``,
+		}},
+		{SQLite{}, []string{
+			`ALTER TABLE "user" ADD COLUMN "name" VARCHAR(64) NOT NULL DEFAULT ''`,
+			`ALTER TABLE "user" ADD COLUMN "nickname" TEXT NULL`,
+			`ALTER TABLE "user" ADD COLUMN "status" INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE "user" ADD COLUMN "score" REAL NOT NULL DEFAULT 0`,
+			``,
+		}},
+	} {
+		model, err := Parse(c.m, nil, &user{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		model.Columns = []*Column{model.Column("id"), model.Column("name"), model.Column("nickname"),
+			model.Column("status"), model.Column("score"), model.Column("created_at")}
+		model.Indexes = nil
+		current := &Table{Name: "user", Columns: []*Column{model.Column("id")}, PrimaryKeys: []string{"id"}}
+		changes := Diff(c.m, model, current)
+		if len(changes) != len(c.want) {
+			t.Fatalf("%s result: %+v", c.m.Name(), changes)
+		}
+		for i, change := range changes {
+			if change.SQL != c.want[i] || change.Destructive {
+				t.Errorf("%s result: %+v\nwant: %s", c.m.Name(), change, c.want[i])
+			}
+		}
+		if last := changes[len(changes)-1]; last.SQL == "" && last.Desc != "add column created_at "+model.Column("created_at").Type+" NOT NULL without a default" {
+			t.Errorf("%s result: %+v", c.m.Name(), last)
+		}
+	}
+}
+
+func TestInspectMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA FROM information_schema.COLUMNS").
+		WithArgs("user").WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA"}).
+		AddRow("id", "bigint(20)", "NO", nil, "auto_increment").
+		AddRow("name", "varchar(64)", "YES", "", ""))
+	mock.ExpectQuery("SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE FROM information_schema.STATISTICS").
+		WithArgs("user").WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "NON_UNIQUE"}).
+		AddRow("PRIMARY", "id", 0).
+		AddRow("uk_name", "name", 0))
+	mock.ExpectQuery("SELECT COLUMN_NAME").WithArgs("none").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA"}))
+
+	tb, err := MySQL{}.Inspect(context.Background(), db, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Table{
+		Name: "user",
+		Columns: []*Column{
+			{Name: "id", Type: "BIGINT(20)", AutoIncrement: true},
+			{Name: "name", Type: "VARCHAR(64)", Nullable: true},
+		},
+		PrimaryKeys: []string{"id"},
+		Indexes:     []*Index{{Name: "uk_name", Columns: []string{"name"}, Unique: true}},
+	}
+	if !reflect.DeepEqual(tb, want) {
+		t.Errorf("result: %+v", tb)
+	}
+	if tb, err := (MySQL{}).Inspect(context.Background(), db, "none"); tb != nil || err != nil {
+		t.Errorf("result: %v, %v", tb, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInspectPostgres(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT column_name, data_type, character_maximum_length, is_nullable, column_default FROM information_schema.columns").
+		WithArgs("user").WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "character_maximum_length", "is_nullable", "column_default"}).
+		AddRow("id", "bigint", nil, "NO", "nextval('user_id_seq'::regclass)").
+		AddRow("name", "character varying", 64, "NO", nil).
+		AddRow("created_at", "timestamp with time zone", nil, "YES", nil))
+	mock.ExpectQuery("SELECT i.relname, a.attname, ix.indisunique, ix.indisprimary FROM pg_index").
+		WithArgs("user").WillReturnRows(sqlmock.NewRows([]string{"relname", "attname", "indisunique", "indisprimary"}).
+		AddRow("user_pkey", "id", true, true).
+		AddRow("idx_name_created", "name", false, false).
+		AddRow("idx_name_created", "created_at", false, false))
+
+	tb, err := Postgres{}.Inspect(context.Background(), db, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Table{
+		Name: "user",
+		Columns: []*Column{
+			{Name: "id", Type: "BIGINT", AutoIncrement: true},
+			{Name: "name", Type: "VARCHAR(64)"},
+			{Name: "created_at", Type: "TIMESTAMPTZ", Nullable: true},
+		},
+		PrimaryKeys: []string{"id"},
+		Indexes:     []*Index{{Name: "idx_name_created", Columns: []string{"name", "created_at"}}},
+	}
+	if !reflect.DeepEqual(tb, want) {
+		t.Errorf("result: %+v", tb)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInspectSQLite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info").
+		WithArgs("userRole").WillReturnRows(sqlmock.NewRows([]string{"name", "type", "notnull", "dflt_value", "pk"}).
+		AddRow("role_id", "INTEGER", 1, nil, 2).
+		AddRow("user_id", "INTEGER", 1, nil, 1))
+	mock.ExpectQuery("SELECT il.name, ii.name, il.\"unique\" FROM pragma_index_list").
+		WithArgs("userRole").WillReturnRows(sqlmock.NewRows([]string{"name", "name", "unique"}))
+
+	tb, err := SQLite{}.Inspect(context.Background(), db, "userRole")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, _ := Parse(SQLite{}, nil, &userRole{})
+	if changes := Diff(SQLite{}, model, tb); len(changes) != 0 || !reflect.DeepEqual(tb.PrimaryKeys, model.PrimaryKeys) {
+		t.Errorf("result: %+v, %+v", tb, changes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
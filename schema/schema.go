@@ -0,0 +1,272 @@
+//Package schema generate DDL of models by the tags of scanner,
+//eg: `db:"name,size:64,not null,index:idx_name"`, see the tag doc of scanner
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rushteam/gosql/scanner"
+)
+
+//Table the schema of a model
+type Table struct {
+	Name        string
+	Columns     []*Column
+	PrimaryKeys []string
+	Indexes     []*Index
+}
+
+//Column ..
+type Column struct {
+	Name string
+	//Type the column type of dialect, eg: VARCHAR(64)
+	Type     string
+	Nullable bool
+	//Default the raw sql of default value, "" means no default
+	Default       string
+	AutoIncrement bool
+}
+
+//Index ..
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+//Column get a column by name
+func (t *Table) Column(name string) *Column {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+//Parse the schema of model by dialect, the model is resolved by mapper (scanner.DefaultMapper if nil)
+//the column type is inferred from the go type unless the type tag is set, a pointer or sql.Null* field is nullable,
+//a single integer primary key is auto increment as Insert does not write it
+func Parse(d Dialect, m *scanner.Mapper, dst interface{}) (*Table, error) {
+	if m == nil {
+		m = scanner.DefaultMapper
+	}
+	dstStruct, err := m.ResolveModelStruct(dst)
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{
+		Name:        dstStruct.TableName(),
+		PrimaryKeys: dstStruct.PrimaryKeys(),
+	}
+	indexes := make(map[string]*Index)
+	for _, k := range dstStruct.Columns() {
+		field := dstStruct.GetStructField(k)
+		col, err := parseColumn(d, field, t.PrimaryKeys)
+		if err != nil {
+			return nil, err
+		}
+		t.Columns = append(t.Columns, col)
+		for _, idx := range fieldIndexes(field, t.Name) {
+			if exist, ok := indexes[idx.Name]; ok {
+				//composite index by the same name
+				exist.Columns = append(exist.Columns, idx.Columns...)
+				exist.Unique = exist.Unique || idx.Unique
+				continue
+			}
+			indexes[idx.Name] = idx
+			t.Indexes = append(t.Indexes, idx)
+		}
+	}
+	return t, nil
+}
+
+//parseColumn ..
+func parseColumn(d Dialect, field *scanner.StructField, pks []string) (*Column, error) {
+	col := &Column{Name: field.Column()}
+	rt := field.Type()
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+		col.Nullable = true
+	}
+	if base, ok := nullTypes[rt]; ok {
+		rt = base
+		col.Nullable = true
+	}
+	if field.HasOption("not null") || field.HasOption("not_null") || isPrimaryKey(col.Name, pks) {
+		col.Nullable = false
+	}
+	var size int
+	if v, ok := field.Option("size"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("schema: invalid size [%s] of column %s", v, col.Name)
+		}
+		size = n
+	}
+	if v, ok := field.Option("type"); ok && v != "" {
+		col.Type = v
+	} else if codec, _ := field.Option("codec"); codec == "json" || field.HasOption("json") {
+		col.Type = d.JSONType()
+	} else if field.HasOption("codec") {
+		col.Type = d.DataType(reflect.TypeOf(""), size)
+	} else {
+		col.Type = d.DataType(rt, size)
+	}
+	if col.Type == "" {
+		return nil, fmt.Errorf("schema: unsupported type %s of column %s, set the type tag", field.Type(), col.Name)
+	}
+	col.Default, _ = field.Option("default")
+	if col.Default == "" && field.HasOption("default") && !col.Nullable {
+		//a bare default tag: the zero value is not inserted, so the db default must be the zero value
+		if col.Default = zeroDefault(col.Type); col.Default == "" {
+			return nil, fmt.Errorf("schema: no zero value of type %s for the default of column %s, set default:value", col.Type, col.Name)
+		}
+	}
+	col.AutoIncrement = field.HasOption("auto") || field.HasOption("auto_increment") ||
+		(len(pks) == 1 && pks[0] == col.Name && isInteger(rt))
+	return col, nil
+}
+
+//fieldIndexes the indexes of field, the default name is idx_{table}_{column} or uk_{table}_{column}
+func fieldIndexes(field *scanner.StructField, table string) []*Index {
+	var list []*Index
+	for _, opt := range []string{"index", "idx"} {
+		if v, ok := field.Option(opt); ok {
+			if v == "" {
+				v = "idx_" + table + "_" + field.Column()
+			}
+			list = append(list, &Index{Name: v, Columns: []string{field.Column()}})
+		}
+	}
+	for _, opt := range []string{"unique_index", "unique", "uni"} {
+		if v, ok := field.Option(opt); ok {
+			if v == "" {
+				v = "uk_" + table + "_" + field.Column()
+			}
+			list = append(list, &Index{Name: v, Columns: []string{field.Column()}, Unique: true})
+		}
+	}
+	return list
+}
+
+func isPrimaryKey(column string, pks []string) bool {
+	for _, pk := range pks {
+		if pk == column {
+			return true
+		}
+	}
+	return false
+}
+
+func isInteger(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+//CreateTable the statements to create the table of model, the first one is CREATE TABLE
+//and the others are CREATE INDEX when the dialect does not define indexes in CREATE TABLE
+//eg: CREATE TABLE `user` (`id` BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT, `name` VARCHAR(64) NOT NULL, UNIQUE KEY `uk_name` (`name`))
+func CreateTable(d Dialect, t *Table, ifNotExists bool) []string {
+	var defs []string
+	inlinePk := len(t.PrimaryKeys) == 1
+	for _, col := range t.Columns {
+		defs = append(defs, ColumnDefinition(d, col, inlinePk && t.PrimaryKeys[0] == col.Name))
+	}
+	if len(t.PrimaryKeys) > 1 {
+		defs = append(defs, "PRIMARY KEY ("+quoteAll(d, t.PrimaryKeys)+")")
+	}
+	if d.InlineIndex() {
+		for _, idx := range t.Indexes {
+			def := "KEY "
+			if idx.Unique {
+				def = "UNIQUE KEY "
+			}
+			defs = append(defs, def+d.Quote(idx.Name)+" ("+quoteAll(d, idx.Columns)+")")
+		}
+	}
+	sql := "CREATE TABLE "
+	if ifNotExists {
+		sql += "IF NOT EXISTS "
+	}
+	stmts := []string{sql + d.Quote(t.Name) + " (" + strings.Join(defs, ", ") + ")"}
+	if !d.InlineIndex() {
+		for _, idx := range t.Indexes {
+			stmts = append(stmts, CreateIndex(d, t.Name, idx, ifNotExists))
+		}
+	}
+	return stmts
+}
+
+//CreateIndex the statement to create an index
+func CreateIndex(d Dialect, table string, idx *Index, ifNotExists bool) string {
+	sql := "CREATE "
+	if idx.Unique {
+		sql += "UNIQUE "
+	}
+	sql += "INDEX "
+	if ifNotExists && !d.InlineIndex() {
+		sql += "IF NOT EXISTS "
+	}
+	return sql + d.Quote(idx.Name) + " ON " + d.Quote(table) + " (" + quoteAll(d, idx.Columns) + ")"
+}
+
+//ColumnDefinition the definition of column, primaryKey defines the column as the single primary key
+//eg: `status` INT NOT NULL DEFAULT 1
+func ColumnDefinition(d Dialect, col *Column, primaryKey bool) string {
+	typ, auto := col.Type, ""
+	if col.AutoIncrement {
+		typ, auto = d.AutoIncrement(col.Type, primaryKey)
+	}
+	def := d.Quote(col.Name) + " " + typ
+	if col.Nullable {
+		def += " NULL"
+	} else {
+		def += " NOT NULL"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	if primaryKey {
+		def += " PRIMARY KEY"
+	}
+	if auto != "" {
+		def += " " + auto
+	}
+	return def
+}
+
+//zeroDefault the zero value of a column type as a default, eg: 0 of INT and an empty string of VARCHAR(64),
+//"" if the type has no zero value (eg: DATETIME)
+func zeroDefault(typ string) string {
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		typ = strings.TrimSpace(typ[:i])
+	}
+	typ = strings.TrimSuffix(typ, " UNSIGNED")
+	switch typ {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT",
+		"FLOAT", "REAL", "DOUBLE", "DOUBLE PRECISION", "DECIMAL", "NUMERIC":
+		return "0"
+	case "BOOLEAN", "BOOL":
+		return "FALSE"
+	case "CHAR", "VARCHAR", "CHARACTER VARYING", "TEXT":
+		return "''"
+	}
+	return ""
+}
+
+func quoteAll(d Dialect, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID        int64          `db:"id"`
+	Name      string         `db:"name,size:64,unique"`
+	Nickname  *string        `db:"nickname"`
+	Email     sql.NullString `db:"email,index:idx_email_status"`
+	Status    int8           `db:"status,default:1,index:idx_email_status"`
+	Score     float64        `db:"score"`
+	Meta      map[string]int `db:"meta,json"`
+	Amount    string         `db:"amount,type:TEXT"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (u *user) TableName() string {
+	return "user"
+}
+
+type userRole struct {
+	UserID int64 `db:"user_id,pk"`
+	RoleID int64 `db:"role_id,pk"`
+}
+
+func TestParse(t *testing.T) {
+	tb, err := Parse(MySQL{}, nil, &user{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.Name != "user" || len(tb.Columns) != 9 || !reflect.DeepEqual(tb.PrimaryKeys, []string{"id"}) {
+		t.Errorf("result: %+v", tb)
+	}
+	want := map[string]Column{
+		"id":         {Name: "id", Type: "BIGINT", AutoIncrement: true},
+		"nickname":   {Name: "nickname", Type: "VARCHAR(255)", Nullable: true},
+		"email":      {Name: "email", Type: "VARCHAR(255)", Nullable: true},
+		"status":     {Name: "status", Type: "TINYINT", Default: "1"},
+		"meta":       {Name: "meta", Type: "JSON"},
+		"amount":     {Name: "amount", Type: "TEXT"},
+		"created_at": {Name: "created_at", Type: "DATETIME"},
+	}
+	for name, col := range want {
+		if got := tb.Column(name); got == nil || *got != col {
+			t.Errorf("column %s: %+v, want %+v", name, got, col)
+		}
+	}
+	if len(tb.Indexes) != 2 || !reflect.DeepEqual(tb.Indexes[1], &Index{Name: "idx_email_status", Columns: []string{"email", "status"}}) {
+		t.Errorf("indexes: %+v", tb.Indexes)
+	}
+	if _, err := Parse(MySQL{}, nil, &struct {
+		Ch chan int `db:"ch"`
+	}{}); err == nil {
+		t.Error("want an error of unsupported type")
+	}
+	tb, err = Parse(Postgres{}, nil, &struct {
+		Status int    `db:"status,default"`
+		Name   string `db:"name,default"`
+		Ok     *bool  `db:"ok,default"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.Column("status").Default != "0" || tb.Column("name").Default != "''" || tb.Column("ok").Default != "" {
+		t.Errorf("result: %+v %+v %+v", tb.Column("status"), tb.Column("name"), tb.Column("ok"))
+	}
+	if _, err := Parse(MySQL{}, nil, &struct {
+		At time.Time `db:"at,default"`
+	}{}); err == nil {
+		t.Error("want an error of no zero default")
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	cases := []struct {
+		d    Dialect
+		dst  interface{}
+		want []string
+	}{
+		{MySQL{}, &user{}, []string{"CREATE TABLE IF NOT EXISTS `user` (`id` BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT, " +
+			"`name` VARCHAR(64) NOT NULL, `nickname` VARCHAR(255) NULL, `email` VARCHAR(255) NULL, " +
+			"`status` TINYINT NOT NULL DEFAULT 1, `score` DOUBLE NOT NULL, `meta` JSON NOT NULL, `amount` TEXT NOT NULL, " +
+			"`created_at` DATETIME NOT NULL, UNIQUE KEY `uk_user_name` (`name`), KEY `idx_email_status` (`email`, `status`))"}},
+		{Postgres{}, &user{}, []string{`CREATE TABLE IF NOT EXISTS "user" ("id" BIGSERIAL NOT NULL PRIMARY KEY, ` +
+			`"name" VARCHAR(64) NOT NULL, "nickname" TEXT NULL, "email" TEXT NULL, ` +
+			`"status" SMALLINT NOT NULL DEFAULT 1, "score" DOUBLE PRECISION NOT NULL, "meta" JSONB NOT NULL, "amount" TEXT NOT NULL, ` +
+			`"created_at" TIMESTAMPTZ NOT NULL)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS "uk_user_name" ON "user" ("name")`,
+			`CREATE INDEX IF NOT EXISTS "idx_email_status" ON "user" ("email", "status")`}},
+		{SQLite{}, &userRole{}, []string{`CREATE TABLE IF NOT EXISTS "userRole" ("user_id" INTEGER NOT NULL, "role_id" INTEGER NOT NULL, ` +
+			`PRIMARY KEY ("user_id", "role_id"))`}},
+	}
+	for _, c := range cases {
+		tb, err := Parse(c.d, nil, c.dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := CreateTable(c.d, tb, true); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\n%q\nwant\n%q", c.d.Name(), got, c.want)
+		}
+	}
+	tb, _ := Parse(SQLite{}, nil, &user{})
+	if got := CreateTable(SQLite{}, tb, false)[0]; got[:57] != `CREATE TABLE "user" ("id" INTEGER NOT NULL PRIMARY KEY AU` {
+		t.Errorf("result: %s", got)
+	}
+	tb, _ = Parse(SQLite{}, nil, &userRole{})
+	tb.Columns = append(tb.Columns, &Column{Name: "seq", Type: "INTEGER", AutoIncrement: true})
+	if got := CreateTable(SQLite{}, tb, false)[0]; got != `CREATE TABLE "userRole" ("user_id" INTEGER NOT NULL, "role_id" INTEGER NOT NULL, `+
+		`"seq" INTEGER NOT NULL, PRIMARY KEY ("user_id", "role_id"))` {
+		t.Errorf("result: %s", got)
+	}
+}
+
+func TestGetDialect(t *testing.T) {
+	if d, ok := GetDialect("MySQL"); !ok || d.Name() != "mysql" {
+		t.Errorf("result: %v, %v", d, ok)
+	}
+	if _, ok := GetDialect("unknown"); ok {
+		t.Error("want not found")
+	}
+}
@@ -4,22 +4,29 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/rushteam/gosql/scanner"
+	"github.com/rushteam/gosql/schema"
 )
 
 //AutoFillCreatedAtAndUpdatedAtField 自动更新时间
-// var AutoFillCreatedAtAndUpdatedAtField = false
+//the fields of AutoFieldCreatedAt and AutoFieldUpdatedAt are filled like the fields tagged autoCreateTime and autoUpdateTime,
+//and UpdateSQL sets AutoFieldUpdatedAt when it is not set
+var AutoFillCreatedAtAndUpdatedAtField = false
 
-// //AutoFieldCreatedAt when insert auto set time
-// var AutoFieldCreatedAt = "created_at"
+//AutoFieldCreatedAt when insert auto set time
+var AutoFieldCreatedAt = "created_at"
 
-// //AutoFieldUpdatedAt when update auto set time
-// var AutoFieldUpdatedAt = "updated_at"
+//AutoFieldUpdatedAt when update auto set time
+var AutoFieldUpdatedAt = "updated_at"
 
-//todo soft delete
-// var AutoFieldDeletedAt = "deleted_at"
+//NowFunc the clock of auto timestamps, it can be replaced in tests
+var NowFunc = time.Now
 
 //Session ..
 type Session struct {
@@ -28,6 +35,31 @@ type Session struct {
 	mutex    sync.RWMutex
 	done     int32
 	ctx      context.Context
+	mapper   *scanner.Mapper
+	//dialect the placeholders of named parameters, nil is "?"
+	dialect schema.Dialect
+}
+
+//Mapper the model mapper of session, see SetNaming
+func (s *Session) Mapper() *scanner.Mapper {
+	if s.mapper == nil {
+		return scanner.DefaultMapper
+	}
+	return s.mapper
+}
+
+//Strict a session on the same executor which scans results in strict mode or not,
+//a *scanner.StrictError is returned when a result column is not found in the model
+//or a field (not tagged optional) of the model is not in the result
+//eg: s.Strict(true).FetchAll(&list)
+func (s *Session) Strict(strict bool) *Session {
+	return &Session{
+		v:        s.v,
+		executor: s.executor,
+		ctx:      s.ctx,
+		mapper:   s.Mapper().Strict(strict),
+		dialect:  s.dialect,
+	}
 }
 
 //Executor ..
@@ -91,115 +123,338 @@ func (s *Session) Exec(query string, args ...interface{}) (sql.Result, error) {
 //Fetch ..
 func (s *Session) Fetch(dst interface{}, opts ...Option) error {
 	debugPrint("db: [session #%v] Fetch()", s.v)
-	dstStruct, err := scanner.ResolveModelStruct(dst)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
+	if err != nil {
+		return err
+	}
+	sql, args, err := s.selectModelSQL(dstStruct, opts...)
 	if err != nil {
 		return err
 	}
-	opts = append(opts, Table(dstStruct.TableName()))
-	sql, args := SelectSQL(opts...)
 	rows, err := s.QueryContext(s.ctx, sql, args...)
 	if err != nil {
 		return err
 	}
-	return scanner.Scan(rows, dst)
+	if err := s.Mapper().Scan(rows, dst); err != nil {
+		return err
+	}
+	return s.callHook(dst, hookAfterFetch)
 }
 
 //FetchAll ..
 func (s *Session) FetchAll(dst interface{}, opts ...Option) error {
 	debugPrint("db: [session #%v] FetchAll()", s.v)
-	dstStruct, err := scanner.ResolveModelStruct(dst)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
+	if err != nil {
+		return err
+	}
+	sql, args, err := s.selectModelSQL(dstStruct, opts...)
+	if err != nil {
+		return err
+	}
+	rows, err := s.QueryContext(s.ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if err := s.Mapper().ScanAll(rows, dst); err != nil {
+		return err
+	}
+	return s.callHook(dst, hookAfterFetch)
+}
+
+//FetchByKey fetch a record by primary key, the key values are in the order of StructData.PrimaryKeys,
+//the key values of dst are used when no key is given, options can follow the key values
+//eg: s.FetchByKey(&UserRole{}, userID, roleID) or s.FetchByKey(&UserRole{UserID: 1, RoleID: 2}, gosql.WithTrashed())
+func (s *Session) FetchByKey(dst interface{}, keys ...interface{}) error {
+	debugPrint("db: [session #%v] FetchByKey()", s.v)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
+	if err != nil {
+		return err
+	}
+	var opts []Option
+	for len(keys) > 0 {
+		opt, ok := keys[len(keys)-1].(Option)
+		if !ok {
+			break
+		}
+		opts = append([]Option{opt}, opts...)
+		keys = keys[:len(keys)-1]
+	}
+	pks := dstStruct.PrimaryKeys()
+	if len(pks) == 0 {
+		return fmt.Errorf("gosql: model %s has no primary key", dstStruct.TableName())
+	}
+	if len(keys) == 0 {
+		fields, err := s.Mapper().ResolveStructValue(dst)
+		if err != nil {
+			return err
+		}
+		for _, pk := range pks {
+			if !isKeyValue(fields[pk]) {
+				return fmt.Errorf("gosql: primary key [%s] of model %s is empty", pk, dstStruct.TableName())
+			}
+			keys = append(keys, fields[pk])
+		}
+	}
+	if len(keys) != len(pks) {
+		return fmt.Errorf("gosql: model %s has %d primary key columns, found %d values", dstStruct.TableName(), len(pks), len(keys))
+	}
+	for i, pk := range pks {
+		opts = append(opts, Where(pk, keys[i]))
+	}
+	return s.Fetch(dst, opts...)
+}
+
+//selectModelSQL the select sql of model, the soft deleted records are excluded
+//unless the option WithTrashed or OnlyTrashed is given
+func (s *Session) selectModelSQL(dstStruct *scanner.StructData, opts ...Option) (string, []interface{}, error) {
+	seg, err := s.segments(_select, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	seg.Table(dstStruct.TableName())
+	scopeSoftDelete(dstStruct, seg)
+	return s.build(seg)
+}
+
+//segments apply options by the mapper of session, a filter struct which can not be resolved is returned as an error
+func (s *Session) segments(cmd uint8, opts ...Option) (seg *SQLSegments, err error) {
+	defer recoverBuild(&err)
+	return newSegments(s.Mapper(), cmd, opts...), nil
+}
+
+//build the sql of seg, the panic of builder (eg: an order by direction from a request is not allowed) is returned as an error
+func (s *Session) build(seg *SQLSegments) (sql string, args []interface{}, err error) {
+	defer recoverBuild(&err)
+	sql, args = seg.Build()
+	return sql, args, nil
+}
+
+//buildSQL apply options and build the sql, see build
+func (s *Session) buildSQL(cmd uint8, opts ...Option) (string, []interface{}, error) {
+	seg, err := s.segments(cmd, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.build(seg)
+}
+
+//recoverBuild recover the panic of builder as an error, a runtime error is still a panic
+func recoverBuild(err *error) {
+	switch r := recover().(type) {
+	case nil:
+	case filterError:
+		*err = r.err
+	case runtime.Error:
+		panic(r)
+	case error:
+		*err = r
+	case string:
+		*err = errors.New("gosql: " + r)
+	default:
+		panic(r)
+	}
+}
+
+//Pluck fetch a column into a slice, eg: var ids []int64; s.Pluck("id", &ids, gosql.Table("user"))
+func (s *Session) Pluck(column string, dst interface{}, opts ...Option) error {
+	debugPrint("db: [session #%v] Pluck()", s.v)
+	opts = append(opts, Columns(column))
+	sql, args, err := s.buildSQL(_select, opts...)
 	if err != nil {
 		return err
 	}
-	opts = append(opts, Table(dstStruct.TableName()))
-	sql, args := SelectSQL(opts...)
 	rows, err := s.QueryContext(s.ctx, sql, args...)
 	if err != nil {
 		return err
 	}
-	return scanner.ScanAll(rows, dst)
+	return s.Mapper().ScanAll(rows, dst)
 }
 
 //Update ..
+//by default the pk and the fields with value nil or "" are not updated,
+//a non-nil pointer field is always updated and a `omitempty` field is not updated when zero,
+//use Only to update the listed fields as they are (nil pointer is NULL), Omit to skip fields
+//and Set(column, nil) to set a column to NULL,
+//a model with a `version` field is locked optimistically, ErrStaleObject is returned when no record is updated,
+//it is an error if only some columns of a composite primary key are set
 func (s *Session) Update(dst interface{}, opts ...Option) (Result, error) {
 	debugPrint("db: [session #%v] Update", s.v)
-	dstStruct, err := scanner.ResolveModelStruct(dst)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
 	if err != nil {
 		return nil, err
 	}
-	fields, err := scanner.ResolveStructValue(dst)
+	if err := s.callHook(dst, hookBeforeUpdate); err != nil {
+		return nil, err
+	}
+	fields, err := s.Mapper().ResolveStructValue(dst)
 	if err != nil {
 		return nil, err
 	}
-	pk := dstStruct.GetPk()
-	if pk != "" {
-		//若主键值不为空则增加主键条件
-		if pkval, ok := fields[pk]; ok {
-			if pkval != "" && pkval != nil {
-				opts = append(opts, Where(pk, pkval))
-			}
+	seg, err := s.segments(_update, opts...)
+	if err != nil {
+		return nil, err
+	}
+	//若主键值不为空则增加主键条件, 复合主键必须全部有值
+	var emptyKeys []string
+	for _, pk := range dstStruct.PrimaryKeys() {
+		if pkval, ok := fields[pk]; ok && isKeyValue(pkval) {
+			seg.Where(pk, pkval)
+		} else {
+			emptyKeys = append(emptyKeys, pk)
+		}
+	}
+	if len(emptyKeys) > 0 && len(emptyKeys) < len(dstStruct.PrimaryKeys()) {
+		return nil, fmt.Errorf("gosql: primary key %v of model %s is empty, all columns of the key must be set", emptyKeys, dstStruct.TableName())
+	}
+	//乐观锁: WHERE version = ? 并且 SET version = version + 1
+	version := versionField(dstStruct)
+	if version != nil {
+		if v := fields[version.Column()]; v != nil {
+			seg.Where(version.Column(), v)
+		} else {
+			seg.Where("[is]"+version.Column(), nil)
 		}
 	}
 	updateFields := make(map[string]interface{}, 0)
-	for k, v := range fields {
-		if k == "" || k == pk {
+	for _, k := range dstStruct.Columns() {
+		field := dstStruct.GetStructField(k)
+		if k == "" || field.IsPrimaryKey() || field == version || !isWritable(field, _update) || !seg.writable(k) {
 			continue
 		}
-		//过滤掉 v 是空的值 todo 会出现指针吗?要是指针怎么处理?
-		if v == nil || v == "" {
+		v, ok := fields[k]
+		if len(seg.only) == 0 && !isUpdateValue(field, v, ok) {
 			continue
 		}
 		updateFields[k] = v
 	}
 	//若开启自动填充时间，则尝试自动填充时间
-	// if AutoFillCreatedAtAndUpdatedAtField == true {
-	// 	//强制填充更新时间
-	// 	updateFields[AutoFieldUpdatedAt] = time.Now()
-	// }
-	opts = append(opts, Table(dstStruct.TableName()))
-	opts = append(opts, Params(updateFields))
-	// for k, v := range updateFields {
-	// 	opts = append(opts, Set(k, v))
-	// }
-	sql, args := UpdateSQL(opts...)
+	fillTimestamps(dstStruct, updateFields, _update, seg)
+	seg.Table(dstStruct.TableName())
+	seg.setParams(updateFields)
+	if version != nil {
+		seg.version = version.Column()
+		seg.setParams(map[string]interface{}{"[+]" + version.Column(): 1})
+	}
+	sql, args, err := s.build(seg)
+	if err != nil {
+		return nil, err
+	}
 	rst, err := s.ExecContext(s.ctx, sql, args...)
+	if err != nil {
+		return rst, err
+	}
+	if version != nil {
+		if n, err := rst.RowsAffected(); err != nil {
+			return rst, err
+		} else if n == 0 {
+			return rst, ErrStaleObject
+		}
+		updateFields[version.Column()] = nextVersion(fields[version.Column()])
+	}
 	//将数据更新到结构体上
-	scanner.UpdateModel(dst, updateFields)
-	return rst, err
+	s.Mapper().UpdateModel(dst, updateFields)
+	return rst, s.callHook(dst, hookAfterUpdate)
+}
+
+//versionField the field tagged version of model for optimistic locking, it must be an integer
+func versionField(dstStruct *scanner.StructData) *scanner.StructField {
+	for _, k := range dstStruct.Columns() {
+		if field := dstStruct.GetStructField(k); field != nil && field.HasOption("version") {
+			return field
+		}
+	}
+	return nil
+}
+
+//nextVersion the version value plus one
+func nextVersion(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(rv.Int() + 1).Convert(rv.Type()).Interface()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(rv.Uint() + 1).Convert(rv.Type()).Interface()
+	}
+	return v
+}
+
+//isKeyValue the value of a primary key column is set
+func isKeyValue(v interface{}) bool {
+	return v != "" && v != nil
+}
+
+//autoIncrementKey the single primary key which is filled by LastInsertId
+func autoIncrementKey(dstStruct *scanner.StructData) string {
+	if pks := dstStruct.PrimaryKeys(); len(pks) == 1 {
+		return pks[0]
+	}
+	return ""
+}
+
+//isUpdateValue the default semantics of updating a field
+func isUpdateValue(field *scanner.StructField, v interface{}, ok bool) bool {
+	//nil pointer is not set
+	if !ok || v == nil {
+		return false
+	}
+	if field.IsPtr() {
+		return true
+	}
+	if field.HasOption("omitempty") {
+		return !reflect.ValueOf(v).IsZero()
+	}
+	//golang现在无法区分字符串 初始化 和 空值
+	return v != ""
 }
 
 //Insert ..
+//the readonly and generated fields are not inserted, a `default` field is not inserted when zero,
+//dst can be a slice of models for batch insert
 func (s *Session) Insert(dst interface{}, opts ...Option) (Result, error) {
 	debugPrint("db: [session #%v] Insert", s.v)
-	dstStruct, err := scanner.ResolveModelStruct(dst)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
 	if err != nil {
 		return nil, err
 	}
-	fields, err := scanner.ResolveStructValue(dst)
+	if err := s.callHook(dst, hookBeforeInsert); err != nil {
+		return nil, err
+	}
+	if dstRV := reflect.Indirect(reflect.ValueOf(dst)); dstRV.Kind() == reflect.Slice {
+		rst, err := s.insertAll(dstStruct, dstRV, opts...)
+		if err != nil {
+			return rst, err
+		}
+		return rst, s.callHook(dst, hookAfterInsert)
+	}
+	fields, err := s.Mapper().ResolveStructValue(dst)
 	if err != nil {
 		return nil, err
 	}
-	pk := dstStruct.GetPk()
+	//the columns of a composite primary key are inserted
+	pk := autoIncrementKey(dstStruct)
 	updateFields := make(map[string]interface{}, 0)
 	for k, v := range fields {
 		//skip pk
 		if k == "" || k == pk {
 			continue
 		}
+		if field := dstStruct.GetStructField(k); !isWritable(field, _insert) || isDefaultValue(field, v) {
+			continue
+		}
 		updateFields[k] = v
 	}
 	//若开启自动填充时间，则尝试自动填充时间
-	// if AutoFillCreatedAtAndUpdatedAtField == true {
-	// 	//强制填充更新时间/创建时间
-	// 	updateFields[AutoFieldUpdatedAt] = time.Now()
-	// 	updateFields[AutoFieldCreatedAt] = time.Now()
-	// }
+	fillTimestamps(dstStruct, updateFields, _insert, nil)
 	opts = append(opts, Table(dstStruct.TableName()))
 	opts = append(opts, Params(updateFields))
 	// for k, v := range updateFields {
 	// 	opts = append(opts, Set(k, v))
 	// }
-	sql, args := InsertSQL(opts...)
+	sql, args, err := s.buildSQL(_insert, opts...)
+	if err != nil {
+		return nil, err
+	}
 	rst, err := s.ExecContext(s.ctx, sql, args...)
 	//将数据更新到结构体上
 	if err == nil {
@@ -207,38 +462,121 @@ func (s *Session) Insert(dst interface{}, opts ...Option) (Result, error) {
 			updateFields[pk], _ = rst.LastInsertId()
 		}
 	}
-	scanner.UpdateModel(dst, updateFields)
+	s.Mapper().UpdateModel(dst, updateFields)
+	if err != nil {
+		return rst, err
+	}
+	return rst, s.callHook(dst, hookAfterInsert)
+}
+
+//insertAll batch insert, a `default` column is not inserted when it is zero in all rows
+func (s *Session) insertAll(dstStruct *scanner.StructData, dstRV reflect.Value, opts ...Option) (Result, error) {
+	if dstRV.Len() == 0 {
+		return nil, errors.New("gosql: Insert called with an empty slice")
+	}
+	rows := make([]map[string]interface{}, dstRV.Len())
+	for i := range rows {
+		fields, err := s.Mapper().ResolveStructValue(dstRV.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		fillTimestamps(dstStruct, fields, _insert, nil)
+		rows[i] = fields
+	}
+	pk := autoIncrementKey(dstStruct)
+	params := make([]map[string]interface{}, len(rows))
+	for i := range params {
+		params[i] = make(map[string]interface{})
+	}
+	for _, k := range dstStruct.Columns() {
+		field := dstStruct.GetStructField(k)
+		if k == "" || k == pk || !isWritable(field, _insert) {
+			continue
+		}
+		omit := true
+		for _, fields := range rows {
+			omit = omit && isDefaultValue(field, fields[k])
+		}
+		if omit {
+			continue
+		}
+		for i, fields := range rows {
+			params[i][k] = fields[k]
+		}
+	}
+	opts = append(opts, Table(dstStruct.TableName()))
+	opts = append(opts, Params(params...))
+	sql, args, err := s.buildSQL(_insert, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rst, err := s.ExecContext(s.ctx, sql, args...)
+	//将填充的时间更新到结构体上
+	if err == nil {
+		for i := range rows {
+			elem := dstRV.Index(i)
+			if elem.Kind() != reflect.Ptr {
+				elem = elem.Addr()
+			}
+			s.Mapper().UpdateModel(elem.Interface(), timestampFields(dstStruct, params[i]))
+		}
+	}
 	return rst, err
 }
 
+//isWritable the field can be written by the statement,
+//readonly and generated fields are never written and insertonly fields are not updated
+func isWritable(field *scanner.StructField, cmd uint8) bool {
+	if field == nil {
+		return true
+	}
+	if field.HasOption("readonly") || field.HasOption("generated") {
+		return false
+	}
+	return cmd != _update || !field.HasOption("insertonly")
+}
+
+//isDefaultValue the value of a `default` field is zero, it is omitted so that the DB default applies
+func isDefaultValue(field *scanner.StructField, v interface{}) bool {
+	if field == nil || !field.HasOption("default") {
+		return false
+	}
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
 //Replace ..
 func (s *Session) Replace(dst interface{}, opts ...Option) (Result, error) {
 	debugPrint("db: [session #%v] Replace", s.v)
-	dstStruct, err := scanner.ResolveModelStruct(dst)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
 	if err != nil {
 		return nil, err
 	}
-	pk := dstStruct.GetPk()
-	fields, err := scanner.ResolveStructValue(dst)
+	if err := s.callHook(dst, hookBeforeInsert); err != nil {
+		return nil, err
+	}
+	pk := autoIncrementKey(dstStruct)
+	fields, err := s.Mapper().ResolveStructValue(dst)
 	if err != nil {
 		return nil, err
 	}
 	updateFields := make(map[string]interface{}, 0)
 	for k, v := range fields {
+		if field := dstStruct.GetStructField(k); !isWritable(field, _replace) || isDefaultValue(field, v) {
+			continue
+		}
 		updateFields[k] = v
 	}
 	//若开启自动填充时间，则尝试自动填充时间
-	// if AutoFillCreatedAtAndUpdatedAtField == true {
-	// 	//强制填充更新时间/创建时间
-	// 	updateFields[AutoFieldUpdatedAt] = time.Now()
-	// 	updateFields[AutoFieldCreatedAt] = time.Now()
-	// }
+	fillTimestamps(dstStruct, updateFields, _replace, nil)
 	opts = append(opts, Table(dstStruct.TableName()))
 	opts = append(opts, Params(updateFields))
 	// for k, v := range updateFields {
 	// 	opts = append(opts, Set(k, v))
 	// }
-	sql, args := ReplaceSQL(opts...)
+	sql, args, err := s.buildSQL(_replace, opts...)
+	if err != nil {
+		return nil, err
+	}
 	rst, err := s.ExecContext(s.ctx, sql, args...)
 	//update model pk
 	if err == nil {
@@ -246,35 +584,80 @@ func (s *Session) Replace(dst interface{}, opts ...Option) (Result, error) {
 			updateFields[pk], _ = rst.LastInsertId()
 		}
 	}
-	scanner.UpdateModel(dst, updateFields)
-	return rst, err
+	s.Mapper().UpdateModel(dst, updateFields)
+	if err != nil {
+		return rst, err
+	}
+	return rst, s.callHook(dst, hookAfterInsert)
 }
 
 //Delete ..
+//a model with a softdelete field is deleted by UPDATE unless the option ForceDelete is given
 func (s *Session) Delete(dst interface{}, opts ...Option) (Result, error) {
 	debugPrint("db: [session #%v] Delete", s.v)
-	dstStruct, err := scanner.ResolveModelStruct(dst)
+	dstStruct, err := s.Mapper().ResolveModelStruct(dst)
 	if err != nil {
 		return nil, err
 	}
-	fields, err := scanner.ResolveStructValue(dst)
+	if err := s.callHook(dst, hookBeforeDelete); err != nil {
+		return nil, err
+	}
+	fields, err := s.Mapper().ResolveStructValue(dst)
 	if err != nil {
 		return nil, err
 	}
-	opts = append(opts, Table(dstStruct.TableName()))
-	pk := dstStruct.GetPk()
-	for k, v := range fields {
-		if k != "" && k == pk {
-			//just use pk,igone other case
-			opts = append(opts, Where(k, v))
+	seg, err := s.segments(_delete, opts...)
+	if err != nil {
+		return nil, err
+	}
+	seg.Table(dstStruct.TableName())
+	softDelete := softDeleteField(dstStruct)
+	//just use pk when all columns of it are set, otherwise all fields
+	columns := dstStruct.PrimaryKeys()
+	for _, pk := range columns {
+		if !isKeyValue(fields[pk]) {
+			columns = dstStruct.Columns()
 			break
-		} else {
-			opts = append(opts, Where(k, v))
 		}
 	}
-	sql, args := DeleteSQL(opts...)
+	if len(columns) == 0 {
+		columns = dstStruct.Columns()
+	}
+	for _, k := range columns {
+		if softDelete != nil && k == softDelete.Column() {
+			continue
+		}
+		if v, ok := fields[k]; ok && k != "" {
+			seg.Where(k, v)
+		}
+	}
+	if softDelete == nil || seg.forceDelete {
+		sql, args, err := s.build(seg)
+		if err != nil {
+			return nil, err
+		}
+		rst, err := s.ExecContext(s.ctx, sql, args...)
+		if err != nil {
+			return rst, err
+		}
+		return rst, s.callHook(dst, hookAfterDelete)
+	}
+	//soft delete
+	deleted := map[string]interface{}{softDelete.Column(): deletedValue(softDelete)}
+	scopeSoftDelete(dstStruct, seg)
+	seg.cmd = _update
+	seg.noAutoTime = true
+	seg.setParams(deleted)
+	sql, args, err := s.build(seg)
+	if err != nil {
+		return nil, err
+	}
 	rst, err := s.ExecContext(s.ctx, sql, args...)
-	return rst, err
+	if err != nil {
+		return rst, err
+	}
+	s.Mapper().UpdateModel(dst, deleted)
+	return rst, s.callHook(dst, hookAfterDelete)
 }
 
 //Commit ..
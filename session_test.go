@@ -2,9 +2,12 @@ package gosql
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rushteam/gosql/scanner"
 )
 
 func TestSession1(t *testing.T) {
@@ -369,3 +372,556 @@ func TestSessionTrans2(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestSessionInsertJSON(t *testing.T) {
+	type Setting struct {
+		Theme string `json:"theme"`
+	}
+	type jsonModel struct {
+		ID      int64   `db:"id,pk"`
+		Setting Setting `db:"setting,json"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("INSERT INTO `jsonModel`").WithArgs(`{"theme":"dark"}`).WillReturnResult(sqlmock.NewResult(2, 1))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	m := &jsonModel{Setting: Setting{"dark"}}
+	if _, err = s.Insert(m); err != nil {
+		t.Error(err)
+	}
+	if m.ID != 2 || m.Setting.Theme != "dark" {
+		t.Errorf("result: %+v", m)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionPluck(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT `id` FROM `test` WHERE `status` = ?").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	var ids []int64
+	if err := s.Pluck("id", &ids, Table("test"), Where("status", 1)); err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("result: %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type userProfile struct {
+	ID       int64
+	NickName string
+}
+
+func TestSessionNaming(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `t_user_profiles`").WillReturnRows(sqlmock.NewRows([]string{"id", "nick_name"}).AddRow(1, "jack"))
+
+	c := mockCluster(db)
+	c = SetNaming(scanner.Naming{TablePrefix: "t_", SnakeTable: true, PluralTable: true})(c)
+	dst := &userProfile{}
+	if err := c.Fetch(dst); err != nil {
+		t.Error(err)
+	}
+	if dst.NickName != "jack" {
+		t.Errorf("result: %v", dst)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type profileFilter struct {
+	NickName string
+}
+
+func TestSessionNamingFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `userProfile` WHERE \\( `NICKNAME` = \\?\\)").WithArgs("jack").
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "NICKNAME"}).AddRow(1, "jack"))
+	mock.ExpectExec("UPDATE userProfile SET NICKNAME = \\? WHERE ID = \\?").WithArgs("tom", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c := mockCluster(db)
+	c = SetNaming(scanner.Naming{Column: strings.ToUpper})(c)
+	dst := &userProfile{}
+	if err := c.Fetch(dst, Where(&profileFilter{NickName: "jack"})); err != nil {
+		t.Error(err)
+	}
+	if dst.ID != 1 || dst.NickName != "jack" {
+		t.Errorf("result: %v", dst)
+	}
+	if _, err := c.NamedExec("UPDATE userProfile SET NICKNAME = :NICKNAME WHERE ID = :ID", &userProfile{ID: 1, NickName: "tom"}); err != nil {
+		t.Error(err)
+	}
+	//the named parameters of a raw condition
+	mock.ExpectQuery("SELECT \\* FROM `userProfile` WHERE NICKNAME = \\? OR \\( ID > \\?\\)").WithArgs("tom", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "NICKNAME"}).AddRow(1, "tom"))
+	if err := c.Fetch(dst, Where("[#]NICKNAME = :NICKNAME", &profileFilter{NickName: "tom"}),
+		OrWhere(Or(&Clause{key: Raw("ID > :ID"), val: &userProfile{}}))); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionBadFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	var list []*updateModel
+	if err := s.FetchAll(&list, Where(&badFilter{Name: "jack"})); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("want an error of duplicate column, found %v", err)
+	}
+	if _, err := s.Update(&updateModel{ID: 1}, Where(&badFilter{Name: "jack"})); err == nil {
+		t.Error("want an error of duplicate column")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionBuildError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	var list []*updateModel
+	if err := s.FetchAll(&list, OrderBy("name sideways")); err == nil || !strings.Contains(err.Error(), "order by direction") {
+		t.Errorf("want an error of order by, found %v", err)
+	}
+	if err := s.Pluck("id", &[]int64{}, Table("test"), OrderBy("id; drop")); err == nil {
+		t.Error("want an error of order by")
+	}
+	if _, err := s.Update(&updateModel{ID: 1, Name: "jack"}, Where("[#]a > :a", map[string]interface{}{})); err == nil {
+		t.Error("want an error of named parameter")
+	}
+	if _, err := s.Delete(&updateModel{ID: 1}, Where("[between]a", 1)); err == nil {
+		t.Error("want an error of between")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionStrict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\* FROM `t_user_profiles`").WillReturnRows(sqlmock.NewRows([]string{"id", "nick"}).AddRow(1, "jack"))
+	mock.ExpectQuery("SELECT \\* FROM `t_user_profiles`").WillReturnRows(sqlmock.NewRows([]string{"id", "nick"}).AddRow(1, "jack"))
+
+	c := mockCluster(db)
+	c = SetStrict(true)(c)
+	c = SetNaming(scanner.Naming{TablePrefix: "t_", SnakeTable: true, PluralTable: true})(c)
+	var list []*userProfile
+	err = c.FetchAll(&list)
+	if _, ok := err.(*scanner.StrictError); !ok {
+		t.Errorf("want StrictError, found %v", err)
+	}
+	s, _ := c.Replica()
+	if err := s.Strict(false).FetchAll(&list); err != nil || len(list) != 1 {
+		t.Errorf("result: %v, %v", list, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type updateModel struct {
+	ID    int64   `db:"id,pk"`
+	Name  string  `db:"name"`
+	Score int     `db:"score,omitempty"`
+	Age   int     `db:"age"`
+	Nick  *string `db:"nick"`
+}
+
+func (u *updateModel) TableName() string {
+	return "test"
+}
+
+func TestSessionUpdateFields(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	empty := ""
+	//"" and zero omitempty are skipped, the non-nil pointer is written
+	mock.ExpectExec("UPDATE `test` SET `age` = \\?, `nick` = \\? WHERE `id` = \\?").
+		WithArgs(0, "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	//only the listed fields, the nil pointer is NULL
+	mock.ExpectExec("UPDATE `test` SET `name` = \\?, `nick` = \\? WHERE `id` = \\?").
+		WithArgs("", nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	//omit and explicit NULL
+	mock.ExpectExec("UPDATE `test` SET `name` = \\?, `score` = \\? WHERE `id` = \\?").
+		WithArgs(nil, 90, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	if _, err := s.Update(&updateModel{ID: 1, Nick: &empty}); err != nil {
+		t.Error(err)
+	}
+	m := &updateModel{ID: 1, Name: "", Nick: nil, Age: 3}
+	if _, err := s.Update(m, Only("name", "nick")); err != nil {
+		t.Error(err)
+	}
+	m = &updateModel{ID: 1, Name: "jack", Score: 90, Nick: &empty}
+	if _, err := s.Update(m, Omit("age", "nick"), Set("name", nil)); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type userRole struct {
+	UserID int64 `db:"user_id,pk"`
+	RoleID int64 `db:"role_id,pk"`
+	Level  int   `db:"level"`
+}
+
+func (u *userRole) TableName() string {
+	return "user_role"
+}
+
+func TestSessionCompositeKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("INSERT INTO `user_role` \\(`level`,`role_id`,`user_id`\\) VALUES \\(\\?,\\?,\\?\\)").
+		WithArgs(1, 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `user_role` SET `level` = \\? WHERE `user_id` = \\? AND `role_id` = \\?").
+		WithArgs(3, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `user_role` WHERE `user_id` = \\? AND `role_id` = \\?").
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT \\* FROM `user_role` WHERE `user_id` = \\? AND `role_id` = \\?").
+		WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "level"}).AddRow(1, 2, 3))
+	mock.ExpectQuery("SELECT \\* FROM `user_role` WHERE `user_id` = \\? AND `role_id` = \\?").
+		WithArgs(5, 6).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "level"}).AddRow(5, 6, 1))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	m := &userRole{UserID: 1, RoleID: 2, Level: 1}
+	if _, err := s.Insert(m); err != nil {
+		t.Error(err)
+	}
+	m.Level = 3
+	if _, err := s.Update(m); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Delete(m); err != nil {
+		t.Error(err)
+	}
+	dst := &userRole{UserID: 1, RoleID: 2}
+	if err := s.FetchByKey(dst); err != nil || dst.Level != 3 {
+		t.Errorf("result: %v, %v", dst, err)
+	}
+	dst = &userRole{}
+	if err := s.FetchByKey(dst, 5, 6); err != nil || dst.UserID != 5 {
+		t.Errorf("result: %v, %v", dst, err)
+	}
+	if err := s.FetchByKey(&userRole{}, 1); err == nil {
+		t.Error("want an error of key values")
+	}
+	//a partial key does not update all roles of the user
+	if _, err := s.Update(&userRole{UserID: 1, Level: 3}); err == nil || !strings.Contains(err.Error(), "role_id") {
+		t.Errorf("want an error of partial key, found %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type accessModel struct {
+	ID        int64  `db:"id,pk"`
+	Name      string `db:"name"`
+	Status    int    `db:"status,default"`
+	Creator   string `db:"creator,insertonly"`
+	Views     int    `db:"views,readonly"`
+	FullName  string `db:"full_name,generated"`
+	CreatedAt string `db:"created_at,default"`
+}
+
+func (a *accessModel) TableName() string {
+	return "test"
+}
+
+func TestSessionAccessTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("INSERT INTO `test` \\(`creator`,`name`\\) VALUES \\(\\?,\\?\\)").
+		WithArgs("admin", "jack").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE `test` SET `name` = \\?, `status` = \\? WHERE `id` = \\?").
+		WithArgs("tom", 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("REPLACE INTO `test` \\(`creator`,`id`,`name`,`status`\\) VALUES \\(\\?,\\?,\\?,\\?\\)").
+		WithArgs("admin", 1, "tom", 2).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `test` \\(`creator`,`name`,`status`\\) VALUES \\(\\?,\\?,\\?\\),\\(\\?,\\?,\\?\\)").
+		WithArgs("", "a", 0, "", "b", 1).WillReturnResult(sqlmock.NewResult(2, 2))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	m := &accessModel{Name: "jack", Creator: "admin", Views: 10, FullName: "x"}
+	if _, err := s.Insert(m); err != nil {
+		t.Error(err)
+	}
+	if m.ID != 1 {
+		t.Errorf("result: %v", m)
+	}
+	m.Name = "tom"
+	m.Status = 2
+	if _, err := s.Update(m, Only("name", "status", "views", "creator")); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Replace(m); err != nil {
+		t.Error(err)
+	}
+	list := []accessModel{{Name: "a"}, {Name: "b", Status: 1}}
+	if _, err := s.Insert(&list); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type timestampModel struct {
+	ID        int64      `db:"id,pk"`
+	Name      string     `db:"name"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+	Created   int64      `db:"created,autoCreateTime"`
+	Updated   int64      `db:"updated,autoUpdateTime:milli"`
+}
+
+func (m *timestampModel) TableName() string {
+	return "test"
+}
+
+func TestSessionTimestamps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	AutoFillCreatedAtAndUpdatedAtField = true
+	defer func() {
+		NowFunc = time.Now
+		AutoFillCreatedAtAndUpdatedAtField = false
+	}()
+	mock.ExpectExec("INSERT INTO `test` \\(`created`,`created_at`,`name`,`updated`,`updated_at`\\) VALUES \\(\\?,\\?,\\?,\\?,\\?\\)").
+		WithArgs(now.Unix(), now, "jack", now.UnixNano()/1e6, now).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE `test` SET `name` = \\?, `updated` = \\?, `updated_at` = \\? WHERE `id` = \\?").
+		WithArgs("tom", now.UnixNano()/1e6, now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `test` SET `name` = \\?, `updated` = \\? WHERE `id` = \\?").
+		WithArgs("tom", int64(1), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `test` \\(`created`,`created_at`,`name`,`updated`,`updated_at`\\) VALUES \\(\\?,\\?,\\?,\\?,\\?\\),\\(\\?,\\?,\\?,\\?,\\?\\)").
+		WithArgs(int64(1), now, "a", now.UnixNano()/1e6, now, now.Unix(), now, "b", now.UnixNano()/1e6, now).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectExec("UPDATE `test` SET `name` = \\?, `updated_at` = \\? WHERE `id` = \\?").
+		WithArgs("tom", now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	m := &timestampModel{Name: "jack"}
+	if _, err := s.Insert(m); err != nil {
+		t.Error(err)
+	}
+	if !m.CreatedAt.Equal(now) || m.UpdatedAt == nil || !m.UpdatedAt.Equal(now) || m.Created != now.Unix() {
+		t.Errorf("result: %v", m)
+	}
+	m.Name = "tom"
+	if _, err := s.Update(m); err != nil {
+		t.Error(err)
+	}
+	//Set and Omit win
+	if _, err := s.Update(m, Set("updated", 1), Omit("updated_at", "created", "created_at")); err != nil {
+		t.Error(err)
+	}
+	list := []timestampModel{{Name: "a", Created: 1}, {Name: "b"}}
+	if _, err := s.Insert(&list); err != nil {
+		t.Error(err)
+	}
+	if list[0].Created != 1 || list[1].Created != now.Unix() || !list[1].CreatedAt.Equal(now) {
+		t.Errorf("result: %v", list)
+	}
+	//UpdateSQL
+	sql, args := UpdateSQL(Table("test"), Set("name", "tom"), Where("id", 1))
+	if _, err := s.Exec(sql, args...); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type softDeleteModel struct {
+	ID        int64      `db:"id,pk"`
+	Name      string     `db:"name"`
+	DeletedAt *time.Time `db:"deleted_at,softdelete"`
+}
+
+func (m *softDeleteModel) TableName() string {
+	return "test"
+}
+
+type softFlagModel struct {
+	ID        int64 `db:"id,pk"`
+	IsDeleted bool  `db:"is_deleted,softdelete"`
+}
+
+func (m *softFlagModel) TableName() string {
+	return "flag"
+}
+
+func TestSessionSoftDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	defer func() { NowFunc = time.Now }()
+	columns := []string{"id", "name", "deleted_at"}
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE \\( `name` = \\? OR `name` = \\?\\) AND `deleted_at` IS NULL").
+		WithArgs("a", "b").WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "a", nil))
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `id` = \\? AND `deleted_at` IS NULL").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "a", nil))
+	mock.ExpectExec("UPDATE `test` SET `deleted_at` = \\? WHERE `id` = \\? AND `deleted_at` IS NULL").
+		WithArgs(now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `id` = \\?$").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "a", now))
+	mock.ExpectQuery("SELECT \\* FROM `test` WHERE `deleted_at` IS NOT NULL").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "a", now))
+	mock.ExpectExec("DELETE FROM `test` WHERE `id` = \\?").
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `flag` SET `is_deleted` = \\? WHERE `id` = \\? AND `is_deleted` = \\?").
+		WithArgs(true, 2, false).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT \\* FROM `flag` WHERE `is_deleted` != \\?").
+		WithArgs(false).WillReturnRows(sqlmock.NewRows([]string{"id", "is_deleted"}).AddRow(2, true))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	var list []*softDeleteModel
+	if err := s.FetchAll(&list, Where("name", "a"), OrWhere("name", "b")); err != nil || len(list) != 1 {
+		t.Errorf("result: %v, %v", list, err)
+	}
+	m := &softDeleteModel{}
+	if err := s.FetchByKey(m, 1); err != nil || m.ID != 1 {
+		t.Errorf("result: %v, %v", m, err)
+	}
+	if _, err := s.Delete(m); err != nil {
+		t.Error(err)
+	}
+	if m.DeletedAt == nil || !m.DeletedAt.Equal(now) {
+		t.Errorf("result: %v", m)
+	}
+	if err := s.FetchByKey(&softDeleteModel{}, 1, WithTrashed()); err != nil {
+		t.Error(err)
+	}
+	if err := s.Fetch(&softDeleteModel{}, OnlyTrashed()); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Delete(m, ForceDelete()); err != nil {
+		t.Error(err)
+	}
+	flag := &softFlagModel{ID: 2}
+	if _, err := s.Delete(flag); err != nil || !flag.IsDeleted {
+		t.Errorf("result: %v, %v", flag, err)
+	}
+	if err := s.FetchAll(&[]softFlagModel{}, OnlyTrashed()); err != nil {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type versionModel struct {
+	ID      int64  `db:"id,pk"`
+	Name    string `db:"name"`
+	Version int    `db:"version,version"`
+}
+
+func (m *versionModel) TableName() string {
+	return "test"
+}
+
+func TestSessionVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("UPDATE `test` SET `version` = `version` \\+ \\?, `name` = \\? WHERE `id` = \\? AND `version` = \\?").
+		WithArgs(1, "tom", 1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `test` SET `version` = `version` \\+ \\?, `name` = \\? WHERE `id` = \\? AND `version` = \\?").
+		WithArgs(1, "jack", 1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s := &Session{v: 0, executor: db, ctx: context.TODO()}
+	m := &versionModel{ID: 1, Name: "tom", Version: 3}
+	stale := *m
+	if _, err := s.Update(m); err != nil {
+		t.Error(err)
+	}
+	if m.Version != 4 {
+		t.Errorf("result: %v", m)
+	}
+	stale.Name = "jack"
+	if _, err := s.Update(&stale); err != ErrStaleObject {
+		t.Errorf("want ErrStaleObject, found %v", err)
+	}
+	if stale.Version != 3 || stale.Name != "jack" {
+		t.Errorf("result: %v", stale)
+	}
+	//the version is written with Only and Omit
+	mock.ExpectExec("UPDATE `test` SET `version` = `version` \\+ \\?, `name` = \\? WHERE `id` = \\? AND `version` = \\?").
+		WithArgs(1, "tom", 1, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := s.Update(m, Only("name")); err != nil {
+		t.Error(err)
+	}
+	mock.ExpectExec("UPDATE `test` SET `version` = `version` \\+ \\? WHERE `id` = \\? AND `version` = \\?").
+		WithArgs(1, 1, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := s.Update(m, Omit("name", "version")); err != nil {
+		t.Error(err)
+	}
+	if m.Version != 6 {
+		t.Errorf("result: %v", m)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,73 @@
+package gosql
+
+import (
+	"reflect"
+
+	"github.com/rushteam/gosql/scanner"
+)
+
+const (
+	//scopeUntrashed the soft deleted records are excluded (default)
+	scopeUntrashed uint8 = iota
+	//scopeWithTrashed the soft deleted records are included
+	scopeWithTrashed
+	//scopeOnlyTrashed only the soft deleted records
+	scopeOnlyTrashed
+)
+
+//softDeleteField the field tagged softdelete of model, nil if the model is not soft deleted
+func softDeleteField(dstStruct *scanner.StructData) *scanner.StructField {
+	for _, k := range dstStruct.Columns() {
+		if field := dstStruct.GetStructField(k); field != nil && field.HasOption("softdelete") {
+			return field
+		}
+	}
+	return nil
+}
+
+//isNullDeleted the softdelete field is NULL when not deleted (a time or pointer field),
+//otherwise it is zero (a bool / integer flag or unix time)
+func isNullDeleted(field *scanner.StructField) bool {
+	rt := field.Type()
+	return field.IsPtr() || rt == timeType || rt == nullTimeType
+}
+
+//deletedValue the value of softdelete field when a record is deleted,
+//a bool or integer field is a flag (true / 1) unless it is unix time by `softdelete:unix` (or milli / nano)
+func deletedValue(field *scanner.StructField) interface{} {
+	rt := field.Type()
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	unit, _ := field.Option("softdelete")
+	if v, ok := timeValue(rt, unit, NowFunc()); ok && (unit != "" || rt == timeType || rt == nullTimeType) {
+		return v
+	}
+	if rt.Kind() == reflect.Bool {
+		return true
+	}
+	return reflect.ValueOf(1).Convert(rt).Interface()
+}
+
+//scopeSoftDelete add the condition of soft deleted records by WithTrashed / OnlyTrashed
+func scopeSoftDelete(dstStruct *scanner.StructData, seg *SQLSegments) {
+	field := softDeleteField(dstStruct)
+	if field == nil || seg.trashed == scopeWithTrashed {
+		return
+	}
+	column := field.Column()
+	if isNullDeleted(field) {
+		if seg.trashed == scopeOnlyTrashed {
+			seg.scope("[!is]"+column, nil)
+		} else {
+			seg.scope("[is]"+column, nil)
+		}
+		return
+	}
+	zero := reflect.Zero(field.Type()).Interface()
+	if seg.trashed == scopeOnlyTrashed {
+		seg.scope("[!=]"+column, zero)
+	} else {
+		seg.scope(column, zero)
+	}
+}
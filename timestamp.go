@@ -0,0 +1,112 @@
+package gosql
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/rushteam/gosql/scanner"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+)
+
+//isCreateTime the field is filled on insert, by tag autoCreateTime or by the name AutoFieldCreatedAt
+func isCreateTime(field *scanner.StructField) bool {
+	if field.HasOption("autoCreateTime") {
+		return true
+	}
+	return AutoFillCreatedAtAndUpdatedAtField && field.Column() == AutoFieldCreatedAt
+}
+
+//isUpdateTime the field is filled on insert and update, by tag autoUpdateTime or by the name AutoFieldUpdatedAt
+func isUpdateTime(field *scanner.StructField) bool {
+	if field.HasOption("autoUpdateTime") {
+		return true
+	}
+	return AutoFillCreatedAtAndUpdatedAtField && field.Column() == AutoFieldUpdatedAt
+}
+
+//timestampValue the value of now for the type of field,
+//integer fields are unix seconds, or milliseconds / nanoseconds by `autoCreateTime:milli` `autoUpdateTime:nano`
+func timestampValue(field *scanner.StructField, now time.Time) (interface{}, bool) {
+	unit, _ := field.Option("autoCreateTime")
+	if v, ok := field.Option("autoUpdateTime"); ok {
+		unit = v
+	}
+	return timeValue(field.Type(), unit, now)
+}
+
+//timeValue the value of now for time.Time, *time.Time, sql.NullTime and integer (unix time in unit) types
+func timeValue(rt reflect.Type, unit string, now time.Time) (interface{}, bool) {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt {
+	case timeType:
+		return now, true
+	case nullTimeType:
+		return sql.NullTime{Time: now, Valid: true}, true
+	}
+	ts := now.Unix()
+	switch unit {
+	case "milli":
+		ts = now.UnixNano() / int64(time.Millisecond)
+	case "nano":
+		ts = now.UnixNano()
+	}
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(ts).Convert(rt).Interface(), true
+	}
+	return nil, false
+}
+
+//fillTimestamps fill the timestamp fields of the values to write,
+//on insert / replace the zero fields are filled, on update the update time fields are always filled
+//and the created time fields are not written, a value set by Set or Params still wins
+func fillTimestamps(dstStruct *scanner.StructData, fields map[string]interface{}, cmd uint8, seg *SQLSegments) {
+	if seg != nil {
+		//the model decides the columns, UpdateSQL does not add AutoFieldUpdatedAt
+		seg.noAutoTime = true
+	}
+	now := NowFunc()
+	for _, k := range dstStruct.Columns() {
+		field := dstStruct.GetStructField(k)
+		if k == "" || field == nil || !isWritable(field, cmd) || (seg != nil && !seg.writable(k)) {
+			continue
+		}
+		if cmd == _update {
+			//the created time is not updated unless it is listed by Only
+			if isCreateTime(field) && !isUpdateTime(field) && len(seg.only) == 0 {
+				delete(fields, k)
+			}
+			if !isUpdateTime(field) || seg.hasParam(k) {
+				continue
+			}
+		} else {
+			if !isCreateTime(field) && !isUpdateTime(field) {
+				continue
+			}
+			if v, ok := fields[k]; ok && v != nil && !reflect.ValueOf(v).IsZero() {
+				continue
+			}
+		}
+		if v, ok := timestampValue(field, now); ok {
+			fields[k] = v
+		}
+	}
+}
+
+//timestampFields the timestamp values of fields which are written back to the model
+func timestampFields(dstStruct *scanner.StructData, fields map[string]interface{}) map[string]interface{} {
+	list := make(map[string]interface{})
+	for k, v := range fields {
+		if field := dstStruct.GetStructField(k); field != nil && (isCreateTime(field) || isUpdateTime(field)) {
+			list[k] = v
+		}
+	}
+	return list
+}